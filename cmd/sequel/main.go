@@ -0,0 +1,166 @@
+package main
+
+/*
+   This utility provides helper commands for working with Sequel contracts
+   from the command line.
+
+   Commands:
+
+     jsoncdc    converts JSON documents (metadata, Evergreen profiles and roles)
+                into JSON-Cadence arguments for `flow transactions send --args-json`
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "jsoncdc":
+		err = runJSONCDC(os.Args[2:], os.Stdin, os.Stdout)
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: sequel <command> [arguments]
+
+Commands:
+  jsoncdc    convert JSON documents into JSON-Cadence transaction arguments
+
+Run 'sequel <command> -h' for command details.`)
+}
+
+func runJSONCDC(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("jsoncdc", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), `Usage: sequel jsoncdc [flags] TYPE:FILE...
+
+Converts JSON documents into a JSON array of JSON-Cadence values, in the given order.
+The output can be passed to 'flow transactions send --args-json'.
+
+TYPE is one of: metadata, profile, role. Use '-' as FILE to read from stdin.
+
+Example:
+  sequel jsoncdc -network testnet metadata:meta.json profile:profile.json
+
+Flags:`)
+		fs.PrintDefaults()
+	}
+
+	var network, address string
+	fs.StringVar(&network, "network", "emulator", "Flow network to resolve contract addresses for (emulator, testnet, mainnet)")
+	fs.StringVar(&address, "address", "", "address of the account with DigitalArt and Evergreen contracts (overrides -network)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no documents to convert")
+	}
+
+	digitalArtAddr, evergreenAddr, err := contractAddresses(network, address)
+	if err != nil {
+		return err
+	}
+
+	values := make([]json.RawMessage, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		docType, path, found := strings.Cut(arg, ":")
+		if !found {
+			return fmt.Errorf("bad argument %q, expected TYPE:FILE", arg)
+		}
+
+		data, err := readDocument(path, stdin)
+		if err != nil {
+			return err
+		}
+
+		var val []byte
+		switch docType {
+		case "metadata":
+			var metadata iinft.DigitalArtMetadata
+			if err = json.Unmarshal(data, &metadata); err == nil {
+				val, err = metadata.MarshalJSONCDC(digitalArtAddr)
+			}
+		case "profile":
+			var profile evergreen.Profile
+			if err = json.Unmarshal(data, &profile); err == nil {
+				val, err = profile.MarshalJSONCDC(evergreenAddr)
+			}
+		case "role":
+			var role evergreen.Role
+			if err = json.Unmarshal(data, &role); err == nil {
+				val, err = role.MarshalJSONCDC(evergreenAddr)
+			}
+		default:
+			return fmt.Errorf("unknown document type %q", docType)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		values = append(values, val)
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(values)
+}
+
+func contractAddresses(network, address string) (flow.Address, flow.Address, error) {
+	if address != "" {
+		addr := flow.HexToAddress(address)
+		if addr == flow.EmptyAddress {
+			return flow.EmptyAddress, flow.EmptyAddress, fmt.Errorf("bad address: %s", address)
+		}
+		return addr, addr, nil
+	}
+
+	client, err := iinft.NewNetworkConnectorEmbedded(network)
+	if err != nil {
+		return flow.EmptyAddress, flow.EmptyAddress, err
+	}
+
+	se, err := iinft.NewTemplateEngine(client)
+	if err != nil {
+		return flow.EmptyAddress, flow.EmptyAddress, err
+	}
+
+	return se.ContractAddress("DigitalArt"), se.ContractAddress("Evergreen"), nil
+}
+
+func readDocument(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunJSONCDC(t *testing.T) {
+	metadata := &iinft.DigitalArtMetadata{
+		Asset:             "did:sequel:asset-id",
+		Name:              "Pure Art",
+		Artist:            "did:sequel:artist",
+		Description:       "Digital art in its purest form",
+		Type:              "Image",
+		ContentURI:        "https://sequel.space/content",
+		ContentPreviewURI: "https://sequel.space/preview",
+		ContentMimetype:   "image/jpeg",
+		Edition:           0,
+		MaxEdition:        4,
+		MetadataURI:       "https://sequel.space/metadata",
+		Record:            "record",
+		AssetHead:         "head",
+	}
+	profile := &evergreen.Profile{
+		ID:          "did:sequel:evergreen",
+		Description: "Test profile",
+		Roles: []*evergreen.Role{
+			{
+				ID:                        evergreen.RoleArtist,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
+				Address:                   flow.HexToAddress("0xf669cb8d41ce0c74"),
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	metadataPath := filepath.Join(dir, "metadata.json")
+	metadataJSON, err := json.Marshal(metadata)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(metadataPath, metadataJSON, 0o600))
+
+	profileJSON, err := json.Marshal(profile)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = runJSONCDC([]string{"-address", "0x179b6b1cb6755e31", "metadata:" + metadataPath, "profile:-"},
+		bytes.NewReader(profileJSON), &out)
+	require.NoError(t, err)
+
+	var values []json.RawMessage
+	require.NoError(t, json.Unmarshal(out.Bytes(), &values))
+	require.Len(t, values, 2)
+
+	var decodedMetadata iinft.DigitalArtMetadata
+	require.NoError(t, decodedMetadata.UnmarshalJSONCDC(values[0]))
+	assert.Equal(t, metadata, &decodedMetadata)
+
+	var decodedProfile evergreen.Profile
+	require.NoError(t, decodedProfile.UnmarshalJSONCDC(values[1]))
+	assert.Equal(t, profile, &decodedProfile)
+
+	assert.Contains(t, string(values[0]), "A.179b6b1cb6755e31.DigitalArt.Metadata")
+}
+
+func TestRunJSONCDC_ExistingMetadata(t *testing.T) {
+	// metadata documents stored by the backend use Go field names
+	metadataJSON := `{
+		"Asset": "did:sequel:asset-id",
+		"Name": "Pure Art",
+		"Artist": "did:sequel:artist",
+		"Description": "Digital art in its purest form",
+		"Type": "Image",
+		"ContentURI": "https://sequel.space/content",
+		"ContentPreviewURI": "https://sequel.space/preview",
+		"ContentMimetype": "image/jpeg",
+		"Edition": 0,
+		"MaxEdition": 4,
+		"MetadataURI": "https://sequel.space/metadata",
+		"Record": "record",
+		"AssetHead": "head"
+	}`
+
+	var out bytes.Buffer
+	err := runJSONCDC([]string{"-address", "0x179b6b1cb6755e31", "metadata:-"}, strings.NewReader(metadataJSON), &out)
+	require.NoError(t, err)
+
+	var values []json.RawMessage
+	require.NoError(t, json.Unmarshal(out.Bytes(), &values))
+	require.Len(t, values, 1)
+
+	var decodedMetadata iinft.DigitalArtMetadata
+	require.NoError(t, decodedMetadata.UnmarshalJSONCDC(values[0]))
+	assert.Equal(t, &iinft.DigitalArtMetadata{
+		Asset:             "did:sequel:asset-id",
+		Name:              "Pure Art",
+		Artist:            "did:sequel:artist",
+		Description:       "Digital art in its purest form",
+		Type:              "Image",
+		ContentURI:        "https://sequel.space/content",
+		ContentPreviewURI: "https://sequel.space/preview",
+		ContentMimetype:   "image/jpeg",
+		Edition:           0,
+		MaxEdition:        4,
+		MetadataURI:       "https://sequel.space/metadata",
+		Record:            "record",
+		AssetHead:         "head",
+	}, &decodedMetadata)
+}
+
+func TestRunJSONCDC_Errors(t *testing.T) {
+	var out bytes.Buffer
+
+	err := runJSONCDC([]string{"metadata"}, strings.NewReader(""), &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected TYPE:FILE")
+
+	err = runJSONCDC([]string{"unknown:-"}, strings.NewReader("{}"), &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown document type")
+
+	err = runJSONCDC([]string{"-address", "0x179b6b1cb6755e31", "profile:-"}, strings.NewReader("not json"), &out)
+	require.Error(t, err)
+}
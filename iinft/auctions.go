@@ -0,0 +1,420 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+)
+
+// AuctionKind mirrors SequelAuctions.AuctionKind enum.
+type AuctionKind uint8
+
+const (
+	EnglishAuction AuctionKind = iota
+	DutchAuction
+)
+
+func (k AuctionKind) String() string {
+	switch k {
+	case EnglishAuction:
+		return "English"
+	case DutchAuction:
+		return "Dutch"
+	default:
+		return "Unknown"
+	}
+}
+
+type (
+	// EnglishAuctionParameters describes an English auction. Prices are decimal strings, i.e. "10.5".
+	EnglishAuctionParameters struct {
+		// StartPrice is the minimum first bid.
+		StartPrice string
+		// ReservePrice is the minimum winning bid. If the highest bid is lower,
+		// the token is returned to the seller when the auction is settled.
+		ReservePrice string
+		// MinIncrement is the minimum difference between consecutive bids.
+		MinIncrement string
+		// StartTime is the time the auction opens for bids. If nil, the auction starts immediately.
+		StartTime *time.Time
+		Duration  time.Duration
+		// ExtensionWindow: a bid placed less than ExtensionWindow before the end of the auction
+		// moves the end to ExtensionWindow after the bid.
+		ExtensionWindow time.Duration
+	}
+
+	// DutchAuctionParameters describes a Dutch auction. Prices are decimal strings, i.e. "10.5".
+	DutchAuctionParameters struct {
+		StartPrice string
+		// FloorPrice is the lowest price the auction falls to.
+		FloorPrice string
+		// PriceDrop is the price reduction per second.
+		PriceDrop string
+		// StartTime is the time the auction opens for bids. If nil, the auction starts immediately.
+		StartTime *time.Time
+		Duration  time.Duration
+	}
+
+	// Auction mirrors SequelAuctions.AuctionDetails structure, as returned by "auction_get_auctions" script.
+	Auction struct {
+		AuctionID        uint64
+		Kind             AuctionKind
+		SellerAddress    flow.Address
+		NFTType          string
+		NFTID            uint64
+		Asset            string
+		PaymentVaultType string
+		StartPrice       evergreen.UFix64
+		// ReservePrice is the minimum winning bid (English) or the floor price (Dutch).
+		ReservePrice evergreen.UFix64
+		// MinIncrement is only used by English auctions.
+		MinIncrement evergreen.UFix64
+		// PriceDrop is the price reduction per second, only used by Dutch auctions.
+		PriceDrop       evergreen.UFix64
+		ExtensionWindow time.Duration
+		StartTime       time.Time
+		EndTime         time.Time
+		// CurrentPrice is the minimum acceptable bid at the time of the query.
+		CurrentPrice  evergreen.UFix64
+		HighestBid    *evergreen.UFix64
+		HighestBidder *flow.Address
+	}
+)
+
+// UFix64ToTime converts a UFix64 Unix timestamp, such as getCurrentBlock().timestamp, into time.Time.
+func UFix64ToTime(v evergreen.UFix64) time.Time {
+	return time.Unix(0, int64(v)*(int64(time.Second)/evergreen.UFix64Factor))
+}
+
+// TimeToUFix64 converts the given time into a UFix64 Unix timestamp.
+func TimeToUFix64(t time.Time) evergreen.UFix64 {
+	return evergreen.UFix64(t.UnixNano() / (int64(time.Second) / evergreen.UFix64Factor))
+}
+
+// DurationToUFix64 converts the given duration into UFix64 seconds.
+func DurationToUFix64(d time.Duration) evergreen.UFix64 {
+	return evergreen.UFix64(d / (time.Second / evergreen.UFix64Factor))
+}
+
+// AuctionFromCadence decodes SequelAuctions.AuctionDetails value.
+func AuctionFromCadence(val cadence.Value) (*Auction, error) {
+	valStruct, ok := val.(cadence.Struct)
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "SequelAuctions.AuctionDetails" {
+		return nil, errors.New("not a SequelAuctions.AuctionDetails value")
+	}
+
+	fields, err := structFields(valStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Auction
+	if res.AuctionID, err = uint64Field(fields, "auctionID"); err != nil {
+		return nil, err
+	}
+	kindEnum, ok := fields["kind"].(cadence.Enum)
+	if !ok {
+		return nil, errors.New("bad kind value")
+	}
+	kind, ok := cadence.SearchFieldByName(kindEnum, "rawValue").(cadence.UInt8)
+	if !ok {
+		return nil, errors.New("bad kind value")
+	}
+	res.Kind = AuctionKind(kind)
+	if res.SellerAddress, err = addressField(fields, "sellerAddress"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = typeField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = typeField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.StartPrice, err = ufix64Field(fields, "startPrice"); err != nil {
+		return nil, err
+	}
+	if res.ReservePrice, err = ufix64Field(fields, "reservePrice"); err != nil {
+		return nil, err
+	}
+	if res.MinIncrement, err = ufix64Field(fields, "minIncrement"); err != nil {
+		return nil, err
+	}
+	if res.PriceDrop, err = ufix64Field(fields, "priceDrop"); err != nil {
+		return nil, err
+	}
+	extensionWindow, err := ufix64Field(fields, "extensionWindow")
+	if err != nil {
+		return nil, err
+	}
+	res.ExtensionWindow = time.Duration(extensionWindow) * (time.Second / evergreen.UFix64Factor)
+	if res.StartTime, err = timeField(fields, "startTime"); err != nil {
+		return nil, err
+	}
+	if res.EndTime, err = timeField(fields, "endTime"); err != nil {
+		return nil, err
+	}
+	if res.CurrentPrice, err = ufix64Field(fields, "currentPrice"); err != nil {
+		return nil, err
+	}
+	if res.HighestBid, err = optionalUFix64Field(fields, "highestBid"); err != nil {
+		return nil, err
+	}
+	if res.HighestBidder, err = optionalAddressField(fields, "highestBidder"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// CreateEnglishAuction escrows the seller's DigitalArt NFT in an English auction,
+// priced in the given fungible token. It returns the auction ID.
+func (c *Client) CreateEnglishAuction(ctx context.Context, seller string, tokenID uint64, params EnglishAuctionParameters, token FungibleTokenContract) (uint64, error) {
+	res, err := c.se.NewTransaction("auction_create_english").
+		SignProposeAndPayAs(seller).
+		UInt64Argument(tokenID).
+		UFix64Argument(params.StartPrice).
+		UFix64Argument(params.ReservePrice).
+		UFix64Argument(params.MinIncrement).
+		Argument(optionalTime(params.StartTime)).
+		Argument(DurationToUFix64(params.Duration).Cadence()).
+		Argument(DurationToUFix64(params.ExtensionWindow).Cadence()).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		RunE(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.createdAuctionID(res)
+}
+
+// CreateDutchAuction escrows the seller's DigitalArt NFT in a Dutch auction,
+// priced in the given fungible token. It returns the auction ID.
+func (c *Client) CreateDutchAuction(ctx context.Context, seller string, tokenID uint64, params DutchAuctionParameters, token FungibleTokenContract) (uint64, error) {
+	res, err := c.se.NewTransaction("auction_create_dutch").
+		SignProposeAndPayAs(seller).
+		UInt64Argument(tokenID).
+		UFix64Argument(params.StartPrice).
+		UFix64Argument(params.FloorPrice).
+		UFix64Argument(params.PriceDrop).
+		Argument(optionalTime(params.StartTime)).
+		Argument(DurationToUFix64(params.Duration).Cadence()).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		RunE(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.createdAuctionID(res)
+}
+
+// PlaceBid escrows the bid on behalf of the bidder, paying with the given fungible token.
+// In a Dutch auction, the first bid that covers the current price wins immediately;
+// any amount above the current price is refunded. If the bid concluded the auction,
+// the returned AuctionSettledEvent is not nil.
+func (c *Client) PlaceBid(ctx context.Context, bidder string, auctionID uint64, amount string, token FungibleTokenContract) (*BidPlacedEvent, *AuctionSettledEvent, error) {
+	res, err := c.se.NewTransaction("auction_bid").
+		SignProposeAndPayAs(bidder).
+		UInt64Argument(auctionID).
+		UFix64Argument(amount).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		RunE(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	placed, err := c.decoder.BidPlacedEvents(res.Events)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(placed) == 0 {
+		return nil, nil, errors.New("BidPlaced event not found")
+	}
+
+	settled, err := c.decoder.AuctionSettledEvents(res.Events)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(settled) > 0 {
+		return placed[0], settled[0], nil
+	}
+
+	return placed[0], nil, nil
+}
+
+// SettleAuction concludes the auction after it has ended. Any account may settle an auction.
+func (c *Client) SettleAuction(ctx context.Context, signer string, auctionID uint64) (*AuctionSettledEvent, error) {
+	res, err := c.se.NewTransaction("auction_settle").
+		SignProposeAndPayAs(signer).
+		UInt64Argument(auctionID).
+		RunE(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	settled, err := c.decoder.AuctionSettledEvents(res.Events)
+	if err != nil {
+		return nil, err
+	}
+	if len(settled) == 0 {
+		return nil, errors.New("AuctionSettled event not found")
+	}
+
+	return settled[0], nil
+}
+
+// ReclaimBid refunds the highest bid and returns the token to the seller, if the auction
+// wasn't settled within the settlement period after its end (see SequelAuctions.SettlementPeriod).
+// Any account may reclaim the bid, as it's always refunded to the highest bidder.
+func (c *Client) ReclaimBid(ctx context.Context, signer string, auctionID uint64) error {
+	_, err := c.se.NewTransaction("auction_reclaim").
+		SignProposeAndPayAs(signer).
+		UInt64Argument(auctionID).
+		RunE(ctx)
+
+	return err
+}
+
+// CancelAuction returns the token to the seller. Auctions with bids can't be cancelled.
+func (c *Client) CancelAuction(ctx context.Context, seller string, auctionID uint64) error {
+	_, err := c.se.NewTransaction("auction_cancel").
+		SignProposeAndPayAs(seller).
+		UInt64Argument(auctionID).
+		RunE(ctx)
+
+	return err
+}
+
+// ClaimAuctionItems transfers the funds in the given token and the DigitalArt tokens
+// that auctions couldn't deliver to the owner (i.e. because the owner's receivers
+// were missing when the auction was settled) into the owner's account.
+func (c *Client) ClaimAuctionItems(ctx context.Context, owner string, token FungibleTokenContract) error {
+	_, err := c.se.NewTransaction("auction_claim").
+		SignProposeAndPayAs(owner).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		RunE(ctx)
+
+	return err
+}
+
+// GetUnclaimedAuctionBalance returns the amount of the given token that auctions hold for the owner.
+func (c *Client) GetUnclaimedAuctionBalance(ctx context.Context, owner flow.Address, token FungibleTokenContract) (evergreen.UFix64, error) {
+	val, err := c.se.NewScript("auction_get_unclaimed_balance").
+		Argument(cadence.NewAddress(owner)).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		RunReturns(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	balance, ok := val.(cadence.UFix64)
+	if !ok {
+		return 0, errors.New("bad auction_get_unclaimed_balance result")
+	}
+
+	return evergreen.UFix64(balance), nil
+}
+
+// GetUnclaimedAuctionNFTs returns the IDs of the tokens that auctions hold for the owner.
+func (c *Client) GetUnclaimedAuctionNFTs(ctx context.Context, owner flow.Address) ([]uint64, error) {
+	val, err := c.se.NewScript("auction_get_unclaimed_nfts").
+		Argument(cadence.NewAddress(owner)).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := val.(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad auction_get_unclaimed_nfts result")
+	}
+
+	res := make([]uint64, len(arr.Values))
+	for i, v := range arr.Values {
+		id, ok := v.(cadence.UInt64)
+		if !ok {
+			return nil, errors.New("bad auction_get_unclaimed_nfts result")
+		}
+		res[i] = uint64(id)
+	}
+
+	return res, nil
+}
+
+// GetAuction returns the auction with the given ID, or nil if it doesn't exist
+// or has been concluded.
+func (c *Client) GetAuction(ctx context.Context, auctionID uint64) (*Auction, error) {
+	val, err := c.se.NewScript("auction_get_auction").
+		UInt64Argument(auctionID).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opt, ok := val.(cadence.Optional)
+	if !ok {
+		return nil, errors.New("bad auction_get_auction result")
+	}
+	if opt.Value == nil {
+		return nil, nil
+	}
+
+	return AuctionFromCadence(opt.Value)
+}
+
+// GetAuctions returns all open auctions created by the seller.
+func (c *Client) GetAuctions(ctx context.Context, seller flow.Address) ([]*Auction, error) {
+	val, err := c.se.NewScript("auction_get_auctions").
+		Argument(cadence.NewAddress(seller)).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := val.(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad auction_get_auctions result")
+	}
+
+	res := make([]*Auction, len(arr.Values))
+	for i, v := range arr.Values {
+		if res[i], err = AuctionFromCadence(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Client) createdAuctionID(res *flow.TransactionResult) (uint64, error) {
+	created, err := c.decoder.AuctionCreatedEvents(res.Events)
+	if err != nil {
+		return 0, err
+	}
+	if len(created) == 0 {
+		return 0, errors.New("AuctionCreated event not found")
+	}
+
+	return created[0].AuctionID, nil
+}
+
+func optionalTime(t *time.Time) cadence.Optional {
+	if t == nil {
+		return cadence.NewOptional(nil)
+	}
+	return cadence.NewOptional(TimeToUFix64(*t).Cadence())
+}
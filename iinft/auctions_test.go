@@ -0,0 +1,29 @@
+package iinft_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUFix64Time(t *testing.T) {
+	ts := time.Unix(1700000000, 123_456_780)
+
+	v := TimeToUFix64(ts)
+	assert.Equal(t, "1700000000.12345678", v.String())
+	assert.True(t, ts.Equal(UFix64ToTime(v)))
+
+	// sub-10ns precision is truncated
+	assert.Equal(t, v, TimeToUFix64(ts.Add(9*time.Nanosecond)))
+
+	assert.Equal(t, evergreen.MustParseUFix64("90.5"), DurationToUFix64(90*time.Second+500*time.Millisecond))
+}
+
+func TestAuctionKind_String(t *testing.T) {
+	assert.Equal(t, "English", EnglishAuction.String())
+	assert.Equal(t, "Dutch", DutchAuction.String())
+	assert.Equal(t, "Unknown", AuctionKind(7).String())
+}
@@ -0,0 +1,406 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	fvmerrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/piprate/splash"
+)
+
+// DefaultBatchMintChunkSize is the number of items minted in a single transaction,
+// unless BatchMintOptions.ChunkSize is set.
+const DefaultBatchMintChunkSize = 50
+
+// DefaultBatchListChunkSize is the number of listings created or withdrawn in a single transaction,
+// unless BatchListOptions.ChunkSize is set.
+const DefaultBatchListChunkSize = 50
+
+var ErrNotMinted = errors.New("edition not minted: the master is not sealed, is paused, has no available editions, or the recipient has no DigitalArt collection")
+
+var ErrNotListed = errors.New("token not listed: it's not in the seller's DigitalArt collection")
+
+var ErrNotWithdrawn = errors.New("listing not withdrawn: it's not in the seller's storefront")
+
+type (
+	// BatchMintItem requests a single edition of the given master
+	// to be minted into the recipient's collection.
+	BatchMintItem struct {
+		MasterID  string
+		Recipient flow.Address
+		// ModID links the item with the Marketplace database. Use zero if not needed.
+		ModID uint64
+	}
+
+	// BatchMintResult describes the outcome of a single BatchMintItem.
+	BatchMintResult struct {
+		Item *BatchMintItem
+		// NFTID and Edition are set if the edition was minted (Err is nil).
+		NFTID   uint64
+		Edition uint64
+		// TransactionID is the ID of the transaction that minted the edition.
+		TransactionID flow.Identifier
+		// Err is ErrNotMinted if the item was skipped by the transaction,
+		// or the transaction error if the item's chunk failed.
+		Err error
+	}
+
+	// BatchMintOptions controls how MintBatch splits items into transactions.
+	BatchMintOptions struct {
+		// ChunkSize is the maximum number of items in a single transaction.
+		// If zero, DefaultBatchMintChunkSize is used. Use EstimateMintBatchChunkSize
+		// to find the chunk size that fits into GasLimit.
+		ChunkSize int
+		// GasLimit is the computation limit of each transaction.
+		// If zero, the connector's default limit is used.
+		GasLimit uint64
+	}
+
+	// BatchListOptions controls how ListBatch and WithdrawBatch split items into transactions.
+	BatchListOptions struct {
+		// ChunkSize is the maximum number of items in a single transaction.
+		// If zero, DefaultBatchListChunkSize is used. Use EstimateListBatchChunkSize
+		// to find the chunk size that fits into GasLimit.
+		ChunkSize int
+		// GasLimit is the computation limit of each transaction.
+		// If zero, the connector's default limit is used.
+		GasLimit uint64
+	}
+
+	// BatchListItem requests a DigitalArt NFT to be listed in the seller's storefront.
+	BatchListItem struct {
+		TokenID uint64
+		// Price is a decimal string, i.e. "10.5".
+		Price        string
+		Token        FungibleTokenContract
+		MetadataLink *string
+	}
+
+	// BatchListResult describes the outcome of a single BatchListItem.
+	BatchListResult struct {
+		Item *BatchListItem
+		// ListingID is set if the token was listed (Err is nil).
+		ListingID uint64
+		// TransactionID is the ID of the transaction that listed the token.
+		TransactionID flow.Identifier
+		// Err is ErrNotListed if the item was skipped by the transaction,
+		// or the transaction error if the item's chunk failed.
+		Err error
+	}
+
+	// BatchWithdrawResult describes the outcome of withdrawing a single listing.
+	BatchWithdrawResult struct {
+		ListingID uint64
+		// TransactionID is the ID of the transaction that withdrew the listing.
+		TransactionID flow.Identifier
+		// Err is ErrNotWithdrawn if the listing was skipped by the transaction,
+		// or the transaction error if the listing's chunk failed.
+		Err error
+	}
+)
+
+// MintBatch mints one edition for each item, possibly from many different masters,
+// using as few transactions as possible. Items are split into chunks of opts.ChunkSize.
+// If a chunk exceeds the computation limit, it's split in half and retried.
+// Items that can't be minted don't fail their chunk; they are reported with ErrNotMinted.
+// MintBatch returns one result per item, in the same order as the items.
+func (c *Client) MintBatch(ctx context.Context, items []*BatchMintItem, opts BatchMintOptions) []*BatchMintResult {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultBatchMintChunkSize
+	}
+
+	results := make([]*BatchMintResult, len(items))
+	for i, item := range items {
+		results[i] = &BatchMintResult{Item: item}
+	}
+
+	for start := 0; start < len(results); start += chunkSize {
+		end := min(start+chunkSize, len(results))
+		c.mintChunk(ctx, results[start:end], opts.GasLimit)
+	}
+
+	return results
+}
+
+func (c *Client) mintChunk(ctx context.Context, results []*BatchMintResult, gasLimit uint64) {
+	items := make([]*BatchMintItem, len(results))
+	for i, r := range results {
+		items[i] = r.Item
+	}
+
+	tb := c.mintBatchTransaction(items)
+	if gasLimit > 0 {
+		tb = tb.Gas(gasLimit)
+	}
+
+	res, err := c.runAsAdmin(ctx, tb)
+	if err != nil {
+		if isComputationLimitError(err) && len(results) > 1 {
+			half := len(results) / 2
+			c.mintChunk(ctx, results[:half], gasLimit)
+			c.mintChunk(ctx, results[half:], gasLimit)
+			return
+		}
+		for _, r := range results {
+			r.Err = err
+		}
+		return
+	}
+
+	c.matchMintedEditions(res, results)
+}
+
+func (c *Client) mintBatchTransaction(items []*BatchMintItem) splash.FlowTransactionBuilder {
+	masterIDs := make([]cadence.Value, len(items))
+	recipients := make([]cadence.Value, len(items))
+	modIDs := make([]cadence.Value, len(items))
+	for i, item := range items {
+		masterIDs[i] = cadence.String(item.MasterID)
+		recipients[i] = cadence.NewAddress(item.Recipient)
+		modIDs[i] = cadence.UInt64(item.ModID)
+	}
+
+	return c.se.NewTransaction("digitalart_mint_batch").
+		Argument(cadence.NewArray(masterIDs)).
+		Argument(cadence.NewArray(recipients)).
+		Argument(cadence.NewArray(modIDs))
+}
+
+// matchMintedEditions assigns Minted events to the items of the chunk. The transaction
+// mints items in order, so events are matched sequentially by asset, MOD ID and recipient.
+func (c *Client) matchMintedEditions(res *flow.TransactionResult, results []*BatchMintResult) {
+	var minted []*MintedEvent
+	recipients := make(map[uint64]*flow.Address)
+	for _, ev := range res.Events {
+		val, err := c.decoder.Decode(ev)
+		if err != nil {
+			continue
+		}
+		switch e := val.(type) {
+		case *MintedEvent:
+			minted = append(minted, e)
+		case *DepositEvent:
+			recipients[e.ID] = e.To
+		}
+	}
+
+	next := 0
+	for _, r := range results {
+		r.TransactionID = res.TransactionID
+		if next < len(minted) {
+			ev := minted[next]
+			to := recipients[ev.ID]
+			if ev.Asset == r.Item.MasterID && ev.ModID == r.Item.ModID && to != nil && *to == r.Item.Recipient {
+				r.NFTID = ev.ID
+				r.Edition = ev.Edition
+				next++
+				continue
+			}
+		}
+		r.Err = ErrNotMinted
+	}
+}
+
+// ListBatch lists the seller's DigitalArt NFTs in their storefront using as few transactions
+// as possible. Items are split into chunks of opts.ChunkSize. If a chunk exceeds
+// the computation limit, it's split in half and retried. Tokens that aren't in the seller's
+// collection don't fail their chunk; they are reported with ErrNotListed.
+// ListBatch returns one result per item, in the same order as the items.
+func (c *Client) ListBatch(ctx context.Context, seller string, items []*BatchListItem, opts BatchListOptions) []*BatchListResult {
+	results := make([]*BatchListResult, len(items))
+	for i, item := range items {
+		results[i] = &BatchListResult{Item: item}
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultBatchListChunkSize
+	}
+	for start := 0; start < len(results); start += chunkSize {
+		end := min(start+chunkSize, len(results))
+		c.listChunk(ctx, seller, results[start:end], opts.GasLimit)
+	}
+
+	return results
+}
+
+func (c *Client) listChunk(ctx context.Context, seller string, results []*BatchListResult, gasLimit uint64) {
+	items := make([]*BatchListItem, len(results))
+	for i, r := range results {
+		items[i] = r.Item
+	}
+
+	tb, err := c.listBatchTransaction(seller, items)
+	if err != nil {
+		for _, r := range results {
+			r.Err = err
+		}
+		return
+	}
+	if gasLimit > 0 {
+		tb = tb.Gas(gasLimit)
+	}
+
+	res, err := tb.RunE(ctx)
+	if err != nil {
+		if isComputationLimitError(err) && len(results) > 1 {
+			half := len(results) / 2
+			c.listChunk(ctx, seller, results[:half], gasLimit)
+			c.listChunk(ctx, seller, results[half:], gasLimit)
+			return
+		}
+		for _, r := range results {
+			r.Err = err
+		}
+		return
+	}
+
+	// The transaction lists items in order, so TokenListed events are matched
+	// sequentially by token ID.
+	listed, err := c.decoder.TokenListedEvents(res.Events)
+	if err != nil {
+		for _, r := range results {
+			r.Err = err
+		}
+		return
+	}
+
+	next := 0
+	for _, r := range results {
+		r.TransactionID = res.TransactionID
+		if next < len(listed) && listed[next].NFTID == r.Item.TokenID {
+			r.ListingID = listed[next].ListingID
+			next++
+			continue
+		}
+		r.Err = ErrNotListed
+	}
+}
+
+func (c *Client) listBatchTransaction(seller string, items []*BatchListItem) (splash.FlowTransactionBuilder, error) {
+	tokenIDs := make([]cadence.Value, len(items))
+	prices := make([]cadence.Value, len(items))
+	ftAddresses := make([]cadence.Value, len(items))
+	ftNames := make([]cadence.Value, len(items))
+	metadataLinks := make([]cadence.Value, len(items))
+	for i, item := range items {
+		price, err := cadence.NewUFix64(item.Price)
+		if err != nil {
+			return splash.FlowTransactionBuilder{}, err
+		}
+		tokenIDs[i] = cadence.UInt64(item.TokenID)
+		prices[i] = price
+		ftAddresses[i] = cadence.NewAddress(item.Token.Address)
+		ftNames[i] = cadence.String(item.Token.Name)
+		metadataLinks[i] = optionalString(item.MetadataLink)
+	}
+
+	return c.se.NewTransaction("marketplace_list_batch").
+		SignProposeAndPayAs(seller).
+		Argument(cadence.NewArray(tokenIDs)).
+		Argument(cadence.NewArray(prices)).
+		Argument(cadence.NewArray(ftAddresses)).
+		Argument(cadence.NewArray(ftNames)).
+		Argument(cadence.NewArray(metadataLinks)), nil
+}
+
+// WithdrawBatch removes the given listings from the seller's storefront, in chunks
+// of opts.ChunkSize listings per transaction. If a chunk exceeds the computation limit,
+// it's split in half and retried. Listings that don't exist are reported with ErrNotWithdrawn.
+// WithdrawBatch returns one result per listing, in the same order as listingIDs.
+func (c *Client) WithdrawBatch(ctx context.Context, seller string, listingIDs []uint64, opts BatchListOptions) []*BatchWithdrawResult {
+	results := make([]*BatchWithdrawResult, len(listingIDs))
+	for i, id := range listingIDs {
+		results[i] = &BatchWithdrawResult{ListingID: id}
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultBatchListChunkSize
+	}
+	for start := 0; start < len(results); start += chunkSize {
+		end := min(start+chunkSize, len(results))
+		c.withdrawChunk(ctx, seller, results[start:end], opts.GasLimit)
+	}
+
+	return results
+}
+
+func (c *Client) withdrawChunk(ctx context.Context, seller string, results []*BatchWithdrawResult, gasLimit uint64) {
+	listingIDs := make([]cadence.Value, len(results))
+	for i, r := range results {
+		listingIDs[i] = cadence.UInt64(r.ListingID)
+	}
+
+	tb := c.se.NewTransaction("marketplace_withdraw_batch").
+		SignProposeAndPayAs(seller).
+		Argument(cadence.NewArray(listingIDs))
+	if gasLimit > 0 {
+		tb = tb.Gas(gasLimit)
+	}
+
+	res, err := tb.RunE(ctx)
+	if err != nil {
+		if isComputationLimitError(err) && len(results) > 1 {
+			half := len(results) / 2
+			c.withdrawChunk(ctx, seller, results[:half], gasLimit)
+			c.withdrawChunk(ctx, seller, results[half:], gasLimit)
+			return
+		}
+		for _, r := range results {
+			r.Err = err
+		}
+		return
+	}
+
+	withdrawn := make(map[uint64]bool)
+	for _, ev := range res.Events {
+		val, err := c.decoder.Decode(ev)
+		if err != nil {
+			continue
+		}
+		if e, ok := val.(*TokenWithdrawnEvent); ok {
+			withdrawn[e.ListingID] = true
+		}
+	}
+
+	for _, r := range results {
+		r.TransactionID = res.TransactionID
+		if withdrawn[r.ListingID] {
+			// the same listing can't be withdrawn twice
+			delete(withdrawn, r.ListingID)
+			continue
+		}
+		r.Err = ErrNotWithdrawn
+	}
+}
+
+// isComputationLimitError reports whether the transaction failed with the FVM's
+// computation limit error.
+func isComputationLimitError(err error) bool {
+	return hasFVMErrorCode(err, fvmerrors.ErrCodeComputationLimitExceededError)
+}
+
+var fvmErrorCodePattern = regexp.MustCompile(`\[Error Code: (\d+)]`)
+
+// hasFVMErrorCode reports whether err is, or wraps, an FVM error with the given code.
+// Transaction results only carry the error message, so unless err wraps a typed FVM error,
+// the codes of the error and its causes are parsed from the message.
+func hasFVMErrorCode(err error, code fvmerrors.ErrorCode) bool {
+	if fvmerrors.HasErrorCode(err, code) {
+		return true
+	}
+
+	for _, match := range fvmErrorCodePattern.FindAllStringSubmatch(err.Error(), -1) {
+		if c, parseErr := strconv.ParseUint(match[1], 10, 16); parseErr == nil && fvmerrors.ErrorCode(c) == code {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,377 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/splash"
+)
+
+type (
+	// Client provides a typed API on top of DigitalArt and SequelMarketplace
+	// transaction templates. It hides template names and the exact order
+	// of template arguments from the caller.
+	Client struct {
+		se           *splash.TemplateEngine
+		client       *splash.Connector
+		decoder      *EventDecoder
+		adminAccount string
+		keyPool      *KeyPool
+	}
+
+	// FungibleTokenContract identifies a fungible token contract that implements
+	// FungibleTokenMetadataViews.FTVaultData view (i.e. FlowToken or ExampleToken).
+	FungibleTokenContract struct {
+		Address flow.Address
+		Name    string
+	}
+)
+
+// NewClient creates a new Client. The template engine must be created for the given
+// connector (see NewTemplateEngine). adminAccount is the name of the account
+// that stores DigitalArt.Admin resource (i.e. "sequel-admin").
+func NewClient(client *splash.Connector, se *splash.TemplateEngine, adminAccount string) *Client {
+	return &Client{
+		se:           se,
+		client:       client,
+		decoder:      NewEventDecoder(se),
+		adminAccount: adminAccount,
+	}
+}
+
+// Engine returns the underlying template engine.
+func (c *Client) Engine() *splash.TemplateEngine {
+	return c.se
+}
+
+// Events returns the event decoder for the client's network.
+func (c *Client) Events() *EventDecoder {
+	return c.decoder
+}
+
+// TokenContract returns a fungible token contract reference for a contract
+// that is well-known to the template engine.
+func (c *Client) TokenContract(name string) (FungibleTokenContract, error) {
+	addr, found := c.se.WellKnownAddresses()[name]
+	if !found {
+		return FungibleTokenContract{}, fmt.Errorf("address not found for contract %s", name)
+	}
+
+	return FungibleTokenContract{
+		Address: flow.HexToAddress(addr),
+		Name:    name,
+	}, nil
+}
+
+// SealMaster saves and freezes the master copy of a digital art
+// that can be used to mint NFT editions.
+func (c *Client) SealMaster(ctx context.Context, metadata *DigitalArtMetadata, profile *evergreen.Profile) error {
+	profileVal, err := evergreen.ProfileToCadence(profile, c.se.ContractAddress("Evergreen"))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runAsAdmin(ctx, c.se.NewTransaction("master_seal").
+		Argument(DigitalArtMetadataToCadence(metadata, c.se.ContractAddress("DigitalArt"))).
+		Argument(profileVal))
+
+	return err
+}
+
+// UpdateMaster replaces the metadata and Evergreen profile of a sealed master.
+// It fails if any editions have been minted from the master.
+func (c *Client) UpdateMaster(ctx context.Context, metadata *DigitalArtMetadata, profile *evergreen.Profile) error {
+	profileVal, err := evergreen.ProfileToCadence(profile, c.se.ContractAddress("Evergreen"))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.runAsAdmin(ctx, c.se.NewTransaction("master_update").
+		Argument(DigitalArtMetadataToCadence(metadata, c.se.ContractAddress("DigitalArt"))).
+		Argument(profileVal))
+
+	return err
+}
+
+// PauseMaster suspends minting from the master until ResumeMaster is called.
+func (c *Client) PauseMaster(ctx context.Context, assetID string) error {
+	return c.runMasterTransaction(ctx, "master_pause", assetID)
+}
+
+// ResumeMaster allows minting from a paused master again.
+func (c *Client) ResumeMaster(ctx context.Context, assetID string) error {
+	return c.runMasterTransaction(ctx, "master_resume", assetID)
+}
+
+// CloseMaster retires all unminted editions of the master. A closed master
+// can never be minted from or sealed again.
+func (c *Client) CloseMaster(ctx context.Context, assetID string) error {
+	return c.runMasterTransaction(ctx, "master_close", assetID)
+}
+
+func (c *Client) runMasterTransaction(ctx context.Context, templateID, assetID string) error {
+	_, err := c.runAsAdmin(ctx, c.se.NewTransaction(templateID).
+		StringArgument(assetID))
+
+	return err
+}
+
+// MintEdition mints the given number of editions from the sealed master
+// and deposits them into the recipient's collection.
+// It returns the IDs of the minted NFTs.
+func (c *Client) MintEdition(ctx context.Context, assetID string, amount uint64, recipient flow.Address) ([]uint64, error) {
+	res, err := c.runAsAdmin(ctx, c.se.NewTransaction("digitalart_mint_edition").
+		StringArgument(assetID).
+		UInt64Argument(amount).
+		Argument(cadence.NewAddress(recipient)))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.mintedIDs(res)
+}
+
+// MintOnDemand mints the given number of editions from the master and sells them
+// to the buyer in a single transaction. The buyer pays unitPrice per edition
+// in the given fungible token, which must implement FungibleTokenMetadataViews.FTVaultData view.
+// The payment is distributed according to the master's Evergreen profile.
+// If params.Metadata is set, the master is sealed first, unless it's already sealed.
+// It returns the IDs of the minted NFTs.
+func (c *Client) MintOnDemand(ctx context.Context, buyer string, assetID string, numEditions uint64, unitPrice string,
+	token FungibleTokenContract, modID uint64, params MintOnDemandParameters) ([]uint64, error) {
+	tb, err := c.mintOnDemandTransaction(buyer, assetID, numEditions, unitPrice, token, modID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.runAsAdmin(ctx, tb)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.mintedIDs(res)
+}
+
+func (c *Client) mintOnDemandTransaction(buyer string, assetID string, numEditions uint64, unitPrice string,
+	token FungibleTokenContract, modID uint64, params MintOnDemandParameters) (splash.FlowTransactionBuilder, error) {
+	script, err := GetMintOnDemandScript(c.se, "digitalart_mint_on_demand", params)
+	if err != nil {
+		return splash.FlowTransactionBuilder{}, err
+	}
+
+	return c.se.NewInlineTransaction(script).
+		PayloadSigner(buyer).
+		StringArgument(assetID).
+		UInt64Argument(numEditions).
+		UFix64Argument(unitPrice).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		UInt64Argument(modID), nil
+}
+
+// ListToken lists the seller's DigitalArt NFT in their NFTStorefront,
+// priced in the given fungible token. It returns the listing ID.
+func (c *Client) ListToken(ctx context.Context, seller string, tokenID uint64, price string, token FungibleTokenContract, metadataLink *string) (uint64, error) {
+	res, err := c.se.NewTransaction("marketplace_list").
+		SignProposeAndPayAs(seller).
+		UInt64Argument(tokenID).
+		UFix64Argument(price).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		Argument(optionalString(metadataLink)).
+		RunE(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.listingID(res)
+}
+
+// ListTokenWithExpiry works like ListToken, but BuyToken refuses to purchase the listing after
+// the given expiry time. NFTStorefront doesn't support expiry, so an expired listing can still
+// be purchased directly from the storefront until it's removed. Run CleanupStaleListings
+// regularly to remove expired listings. The expiry is kept in the seller's account.
+func (c *Client) ListTokenWithExpiry(ctx context.Context, seller string, tokenID uint64, price string, token FungibleTokenContract, metadataLink *string, expiry time.Time) (uint64, error) {
+	res, err := c.se.NewTransaction("marketplace_list_with_expiry").
+		SignProposeAndPayAs(seller).
+		UInt64Argument(tokenID).
+		UFix64Argument(price).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		Argument(optionalString(metadataLink)).
+		Argument(TimeToUFix64(expiry).Cadence()).
+		RunE(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.listingID(res)
+}
+
+// PriceOption is one of the currencies accepted by a multi-currency listing.
+type PriceOption struct {
+	// Price is a decimal string, i.e. "10.5".
+	Price string
+	Token FungibleTokenContract
+}
+
+// ListTokenWithPrices lists the seller's DigitalArt NFT in their NFTStorefront,
+// accepting payment in any of the given currencies. It creates one storefront listing
+// per price option, so each currency may appear only once. When the token is sold in
+// one currency, the listings in other currencies are removed, if the seller's storefront
+// allows cleanup, or become stale listings for CleanupStaleListings to remove.
+// If expiry is not nil, BuyToken refuses to purchase the listings after the given time
+// (see ListTokenWithExpiry). It returns the listing prices, in the same order as the price options.
+func (c *Client) ListTokenWithPrices(ctx context.Context, seller string, tokenID uint64, prices []PriceOption, metadataLink *string, expiry *time.Time) ([]*ListingPrice, error) {
+	priceValues := make([]cadence.Value, len(prices))
+	ftAddresses := make([]cadence.Value, len(prices))
+	ftNames := make([]cadence.Value, len(prices))
+	for i, p := range prices {
+		price, err := cadence.NewUFix64(p.Price)
+		if err != nil {
+			return nil, err
+		}
+		priceValues[i] = price
+		ftAddresses[i] = cadence.NewAddress(p.Token.Address)
+		ftNames[i] = cadence.String(p.Token.Name)
+	}
+
+	res, err := c.se.NewTransaction("marketplace_list_with_prices").
+		SignProposeAndPayAs(seller).
+		UInt64Argument(tokenID).
+		Argument(cadence.NewArray(priceValues)).
+		Argument(cadence.NewArray(ftAddresses)).
+		Argument(cadence.NewArray(ftNames)).
+		Argument(optionalString(metadataLink)).
+		Argument(optionalTime(expiry)).
+		RunE(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listed, err := c.decoder.TokenListedEvents(res.Events)
+	if err != nil {
+		return nil, err
+	}
+	if len(listed) == 0 {
+		return nil, errors.New("TokenListed event not found")
+	}
+
+	return listed[0].Prices, nil
+}
+
+// BuyToken purchases the listed NFT on behalf of the buyer, paying with
+// the given fungible token. It returns the ID of the purchased NFT.
+func (c *Client) BuyToken(ctx context.Context, buyer string, storefront flow.Address, listingID uint64, token FungibleTokenContract, metadataLink *string) (uint64, error) {
+	res, err := c.se.NewTransaction("marketplace_buy").
+		SignProposeAndPayAs(buyer).
+		UInt64Argument(listingID).
+		Argument(cadence.NewAddress(storefront)).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		Argument(optionalString(metadataLink)).
+		RunE(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sold, err := c.decoder.TokenSoldEvents(res.Events)
+	if err != nil {
+		return 0, err
+	}
+	if len(sold) == 0 {
+		return 0, errors.New("TokenSold event not found")
+	}
+
+	return sold[0].NFTID, nil
+}
+
+// WithdrawListing removes the listing from the seller's NFTStorefront.
+func (c *Client) WithdrawListing(ctx context.Context, seller string, listingID uint64) error {
+	_, err := c.se.NewTransaction("marketplace_withdraw").
+		SignProposeAndPayAs(seller).
+		UInt64Argument(listingID).
+		RunE(ctx)
+
+	return err
+}
+
+// Transfer moves the owner's DigitalArt NFT to the recipient's collection.
+func (c *Client) Transfer(ctx context.Context, owner string, tokenID uint64, recipient flow.Address) error {
+	_, err := c.se.NewTransaction("digitalart_transfer").
+		SignProposeAndPayAs(owner).
+		UInt64Argument(tokenID).
+		Argument(cadence.NewAddress(recipient)).
+		RunE(ctx)
+
+	return err
+}
+
+// GetOwnerHistory returns distinct addresses of up to 11 most recent owners of the token
+// in the owner's collection (the current owner and 10 previous ones), from the earliest
+// to the most recent one. Owners are recorded when the token is sold in Sequel marketplaces,
+// not when it's transferred. Use evergreen.Collectors to find out which of them receive
+// the Collector role's commission when the token is sold.
+func (c *Client) GetOwnerHistory(ctx context.Context, owner flow.Address, tokenID uint64) ([]flow.Address, error) {
+	val, err := c.se.NewScript("digitalart_get_owner_history").
+		Argument(cadence.NewAddress(owner)).
+		UInt64Argument(tokenID).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := val.(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad digitalart_get_owner_history result")
+	}
+
+	res := make([]flow.Address, len(arr.Values))
+	for i, v := range arr.Values {
+		addr, ok := v.(cadence.Address)
+		if !ok {
+			return nil, errors.New("bad digitalart_get_owner_history result")
+		}
+		res[i] = flow.Address(addr)
+	}
+
+	return res, nil
+}
+
+func (c *Client) listingID(res *flow.TransactionResult) (uint64, error) {
+	listed, err := c.decoder.TokenListedEvents(res.Events)
+	if err != nil {
+		return 0, err
+	}
+	if len(listed) == 0 {
+		return 0, errors.New("TokenListed event not found")
+	}
+
+	return listed[0].ListingID, nil
+}
+
+func (c *Client) mintedIDs(res *flow.TransactionResult) ([]uint64, error) {
+	minted, err := c.decoder.MintedEvents(res.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, len(minted))
+	for i, ev := range minted {
+		ids[i] = ev.ID
+	}
+
+	return ids, nil
+}
+
+func optionalString(val *string) cadence.Optional {
+	if val == nil {
+		return cadence.NewOptional(nil)
+	}
+	return cadence.NewOptional(cadence.String(*val))
+}
@@ -1,22 +1,520 @@
 package iinft
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flowkit/v2"
 	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/gateway"
+	contracts "github.com/piprate/sequel-flow-contracts"
+	"github.com/piprate/splash"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix is the prefix of environment variables read by ConfigFromEnv.
+const DefaultEnvPrefix = "SEQUEL_FLOW_"
+
+var (
+	ErrNoNetwork = errors.New("network not specified")
+	ErrNoHost    = errors.New("access node host not specified")
+
+	// embeddedConfigFiles maps network names to Flow configuration files embedded in ResourcesFS.
+	embeddedConfigFiles = map[string]string{
+		"emulator": "flow.json",
+		"testnet":  "flow.testnet.json",
+		"mainnet":  "flow.mainnet.json",
+	}
 )
 
+type (
+	// Config describes a Flow network, the addresses of contracts used by transaction
+	// and script templates, and the signer accounts. Unlike flow.json, it doesn't require
+	// any files on disk: it can be built in code, read from a YAML file (see LoadConfig),
+	// from environment variables (see ConfigFromEnv) or from the embedded Flow configuration
+	// (see EmbeddedConfig).
+	Config struct {
+		// Network is the network name, i.e. "mainnet", "testnet" or "emulator".
+		Network string
+		// Host is the access node address, i.e. "access.mainnet.nodes.onflow.org:9000".
+		Host string
+		// Contracts maps contract names to the addresses they are deployed to.
+		Contracts map[string]flow.Address
+		// Accounts maps account names, without the network prefix (i.e. "sequel-admin"),
+		// to signer accounts.
+		Accounts map[string]*AccountConfig
+	}
+
+	// AccountConfig describes a signer account.
+	AccountConfig struct {
+		Address flow.Address
+		// Key is the account key. If not set, the key is built from PrivateKey, KeyFile
+		// or SignerURL, in this order. Accounts without a key can't sign transactions
+		// and aren't available to the template engine.
+		Key accounts.Key
+		// PrivateKey is the hex-encoded private key.
+		PrivateKey string
+		// KeyFile is the path to a file with the hex-encoded private key.
+		KeyFile string
+		// SignerURL is the URL of the remote signer (see RemoteKey).
+		SignerURL string
+		// PublicKey is the hex-encoded public key of the remote signer.
+		PublicKey string
+		KeyIndex  uint32
+		// SigAlgo is the signature algorithm. Defaults to ECDSA_P256.
+		SigAlgo string
+		// HashAlgo is the hash algorithm. Defaults to SHA3_256.
+		HashAlgo string
+	}
+
+	configFile struct {
+		Network   string                        `yaml:"network"`
+		Host      string                        `yaml:"host"`
+		Contracts map[string]string             `yaml:"contracts"`
+		Accounts  map[string]*accountConfigFile `yaml:"accounts"`
+	}
+
+	accountConfigFile struct {
+		Address    string `yaml:"address"`
+		PrivateKey string `yaml:"privateKey"`
+		KeyFile    string `yaml:"keyFile"`
+		SignerURL  string `yaml:"signerURL"`
+		PublicKey  string `yaml:"publicKey"`
+		KeyIndex   uint32 `yaml:"keyIndex"`
+		SigAlgo    string `yaml:"sigAlgo"`
+		HashAlgo   string `yaml:"hashAlgo"`
+	}
+
+	// flowJSON is the subset of flow.json format used by EmbeddedConfig.
+	flowJSON struct {
+		Contracts   map[string]json.RawMessage              `json:"contracts"`
+		Networks    map[string]json.RawMessage              `json:"networks"`
+		Accounts    map[string]flowJSONAccount              `json:"accounts"`
+		Deployments map[string]map[string][]json.RawMessage `json:"deployments"`
+	}
+
+	flowJSONAccount struct {
+		Address string          `json:"address"`
+		Key     json.RawMessage `json:"key"`
+	}
+)
+
+// LoadFlowKitAccount creates a flowkit account named "Sequel" from the given address and
+// hex-encoded private key. The key must be an ECDSA_P256/SHA3_256 key with index 0.
+// Use LoadFlowKitAccountWithKey for other keys.
 func LoadFlowKitAccount(addrStr, keyStr string) (accounts.Account, error) {
-	acct := accounts.Account{}
+	key, err := NewInMemoryKey(keyStr, KeyOptions{})
+	if err != nil {
+		return accounts.Account{}, err
+	}
+
+	return LoadFlowKitAccountWithKey("Sequel", addrStr, key), nil
+}
+
+// LoadFlowKitAccountWithKey creates a flowkit account with the given name, address and key.
+// The key may be created by NewInMemoryKey, NewFileKey or NewRemoteKey with any KeyOptions.
+func LoadFlowKitAccountWithKey(name, addrStr string, key accounts.Key) accounts.Account {
+	return accounts.Account{
+		Name:    name,
+		Address: flow.HexToAddress(addrStr),
+		Key:     key,
+	}
+}
+
+// EmbeddedConfig returns the configuration for the given network ("mainnet", "testnet" or "emulator")
+// from the Flow configuration files embedded in this module. Private keys are only included
+// if they are stored in the files as plain hex strings (i.e. for the emulator), so signer keys
+// for other networks need to be added in code or with ApplyEnv.
+func EmbeddedConfig(network string) (*Config, error) {
+	fileName, found := embeddedConfigFiles[network]
+	if !found {
+		return nil, fmt.Errorf("no embedded configuration for network %s", network)
+	}
+
+	data, err := contracts.ResourcesFS.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var file flowJSON
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		Network:   network,
+		Contracts: make(map[string]flow.Address),
+		Accounts:  make(map[string]*AccountConfig),
+	}
+
+	if hostVal, found := file.Networks[network]; found {
+		var host string
+		if err = json.Unmarshal(hostVal, &host); err != nil {
+			var networkDef struct {
+				Host string `json:"host"`
+			}
+			if err = json.Unmarshal(hostVal, &networkDef); err != nil {
+				return nil, fmt.Errorf("bad network %s: %w", network, err)
+			}
+			host = networkDef.Host
+		}
+		cfg.Host = host
+	}
+
+	for name, contractVal := range file.Contracts {
+		var contract struct {
+			Aliases map[string]string `json:"aliases"`
+		}
+		// contracts without aliases are defined by a plain source path
+		if err = json.Unmarshal(contractVal, &contract); err != nil {
+			continue
+		}
+		if addr, found := contract.Aliases[network]; found {
+			cfg.Contracts[name] = flow.HexToAddress(addr)
+		}
+	}
+
+	prefix := network + "-"
+	for name, acct := range file.Accounts {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		acctCfg := &AccountConfig{
+			Address: flow.HexToAddress(acct.Address),
+		}
+		var key string
+		if err = json.Unmarshal(acct.Key, &key); err == nil && !strings.HasPrefix(key, "$") {
+			acctCfg.PrivateKey = key
+		} else {
+			var keyDef struct {
+				Type       string `json:"type"`
+				Index      uint32 `json:"index"`
+				SigAlgo    string `json:"signatureAlgorithm"`
+				HashAlgo   string `json:"hashAlgorithm"`
+				PrivateKey string `json:"privateKey"`
+			}
+			if err = json.Unmarshal(acct.Key, &keyDef); err == nil && keyDef.Type == "hex" && !strings.HasPrefix(keyDef.PrivateKey, "$") {
+				acctCfg.PrivateKey = keyDef.PrivateKey
+				acctCfg.KeyIndex = keyDef.Index
+				acctCfg.SigAlgo = keyDef.SigAlgo
+				acctCfg.HashAlgo = keyDef.HashAlgo
+			}
+		}
+		cfg.Accounts[strings.TrimPrefix(name, prefix)] = acctCfg
+	}
+
+	for acctName, contractVals := range file.Deployments[network] {
+		acct, found := file.Accounts[acctName]
+		if !found {
+			return nil, fmt.Errorf("deployment account %s not found", acctName)
+		}
+		for _, contractVal := range contractVals {
+			var name string
+			if err = json.Unmarshal(contractVal, &name); err != nil {
+				var contract struct {
+					Name string `json:"name"`
+				}
+				if err = json.Unmarshal(contractVal, &contract); err != nil {
+					return nil, fmt.Errorf("bad deployment for account %s: %w", acctName, err)
+				}
+				name = contract.Name
+			}
+			cfg.Contracts[name] = flow.HexToAddress(acct.Address)
+		}
+	}
+
+	return cfg, nil
+}
+
+// LoadConfig reads the configuration from the given YAML file:
+//
+//	network: mainnet
+//	host: access.mainnet.nodes.onflow.org:9000
+//	contracts:
+//	  SequelMarketplace: "0x9a02a1d17295f3e7"
+//	accounts:
+//	  sequel-admin:
+//	    address: "0x9a02a1d17295f3e7"
+//	    privateKey: "..."
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file configFile
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		Network:   file.Network,
+		Host:      file.Host,
+		Contracts: make(map[string]flow.Address, len(file.Contracts)),
+		Accounts:  make(map[string]*AccountConfig, len(file.Accounts)),
+	}
+	for name, addr := range file.Contracts {
+		cfg.Contracts[name] = flow.HexToAddress(addr)
+	}
+	for name, acct := range file.Accounts {
+		cfg.Accounts[name] = &AccountConfig{
+			Address:    flow.HexToAddress(acct.Address),
+			PrivateKey: acct.PrivateKey,
+			KeyFile:    acct.KeyFile,
+			SignerURL:  acct.SignerURL,
+			PublicKey:  acct.PublicKey,
+			KeyIndex:   acct.KeyIndex,
+			SigAlgo:    acct.SigAlgo,
+			HashAlgo:   acct.HashAlgo,
+		}
+	}
+
+	return cfg, nil
+}
+
+// ConfigFromEnv builds the configuration from environment variables with the given prefix
+// (DefaultEnvPrefix if empty). See ApplyEnv for the list of variables.
+func ConfigFromEnv(prefix string) (*Config, error) {
+	cfg := &Config{}
+	if err := cfg.ApplyEnv(prefix); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyEnv overrides the configuration with environment variables with the given prefix
+// (DefaultEnvPrefix if empty):
+//
+//	<prefix>NETWORK                   network name
+//	<prefix>HOST                      access node host
+//	<prefix>CONTRACT_<NAME>           contract address, i.e. SEQUEL_FLOW_CONTRACT_SEQUELMARKETPLACE
+//	<prefix>ACCOUNT_<NAME>_ADDRESS    account address, i.e. SEQUEL_FLOW_ACCOUNT_SEQUEL_ADMIN_ADDRESS
+//	<prefix>ACCOUNT_<NAME>_KEY        hex-encoded private key
+//	<prefix>ACCOUNT_<NAME>_KEY_FILE   path to the file with the hex-encoded private key
+//	<prefix>ACCOUNT_<NAME>_SIGNER_URL remote signer URL
+//	<prefix>ACCOUNT_<NAME>_PUBLIC_KEY remote signer's hex-encoded public key
+//	<prefix>ACCOUNT_<NAME>_KEY_INDEX  key index
+//	<prefix>ACCOUNT_<NAME>_SIG_ALGO   signature algorithm
+//	<prefix>ACCOUNT_<NAME>_HASH_ALGO  hash algorithm
+//
+// Contract names are matched case-insensitively against known contracts. Account names
+// are lower-cased, with underscores replaced by hyphens, so SEQUEL_ADMIN becomes "sequel-admin".
+func (c *Config) ApplyEnv(prefix string) error {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+
+	if c.Contracts == nil {
+		c.Contracts = make(map[string]flow.Address)
+	}
+	if c.Accounts == nil {
+		c.Accounts = make(map[string]*AccountConfig)
+	}
+
+	knownContracts := make(map[string]string)
+	for _, name := range append(requiredWellKnownContracts, optionalWellKnownContracts...) {
+		knownContracts[strings.ToUpper(name)] = name
+	}
+	for name := range c.Contracts {
+		knownContracts[strings.ToUpper(name)] = name
+	}
+
+	for _, entry := range os.Environ() {
+		key, val, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		key = strings.TrimPrefix(key, prefix)
+
+		switch {
+		case key == "NETWORK":
+			c.Network = val
+		case key == "HOST":
+			c.Host = val
+		case strings.HasPrefix(key, "CONTRACT_"):
+			name := strings.TrimPrefix(key, "CONTRACT_")
+			if knownName, found := knownContracts[strings.ToUpper(name)]; found {
+				name = knownName
+			}
+			c.Contracts[name] = flow.HexToAddress(val)
+		case strings.HasPrefix(key, "ACCOUNT_"):
+			if err := c.applyAccountEnv(strings.TrimPrefix(key, "ACCOUNT_"), val); err != nil {
+				return fmt.Errorf("bad %s%s value: %w", prefix, key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) applyAccountEnv(key, val string) error {
+	// longer suffixes go first: _KEY_INDEX must not be mistaken for _KEY
+	for _, suffix := range []string{"_KEY_INDEX", "_KEY_FILE", "_PUBLIC_KEY", "_SIGNER_URL", "_SIG_ALGO", "_HASH_ALGO", "_ADDRESS", "_KEY"} {
+		if !strings.HasSuffix(key, suffix) {
+			continue
+		}
+
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimSuffix(key, suffix)), "_", "-")
+		acct, found := c.Accounts[name]
+		if !found {
+			acct = &AccountConfig{}
+			c.Accounts[name] = acct
+		}
+
+		switch suffix {
+		case "_ADDRESS":
+			acct.Address = flow.HexToAddress(val)
+		case "_KEY":
+			acct.PrivateKey = val
+		case "_KEY_FILE":
+			acct.KeyFile = val
+		case "_SIGNER_URL":
+			acct.SignerURL = val
+		case "_PUBLIC_KEY":
+			acct.PublicKey = val
+		case "_KEY_INDEX":
+			index, err := strconv.ParseUint(val, 10, 32)
+			if err != nil {
+				return err
+			}
+			acct.KeyIndex = uint32(index)
+		case "_SIG_ALGO":
+			acct.SigAlgo = val
+		case "_HASH_ALGO":
+			acct.HashAlgo = val
+		}
+
+		return nil
+	}
+
+	return errors.New("unknown account setting")
+}
+
+// Validate checks that the configuration is complete.
+func (c *Config) Validate() error {
+	if c.Network == "" {
+		return ErrNoNetwork
+	}
+	if c.Host == "" {
+		return ErrNoHost
+	}
+	for name, acct := range c.Accounts {
+		if acct.Address == flow.EmptyAddress {
+			return fmt.Errorf("account %s has no address", name)
+		}
+	}
+	return nil
+}
+
+// State builds flowkit state from the configuration. Accounts are named "<network>-<name>",
+// as expected by splash.Connector.
+func (c *Config) State() (*flowkit.State, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := flowkit.Init(splash.NewEmbedLoader(&contracts.ResourcesFS))
+	if err != nil {
+		return nil, err
+	}
+
+	state.Networks().AddOrUpdate(config.Network{Name: c.Network, Host: c.Host})
+
+	for name, addr := range c.Contracts {
+		state.Contracts().AddOrUpdate(config.Contract{
+			Name:    name,
+			Aliases: config.Aliases{{Network: c.Network, Address: addr}},
+		})
+	}
+
+	for name, acct := range c.Accounts {
+		if acct.Key == nil && acct.PrivateKey == "" && acct.KeyFile == "" && acct.SignerURL == "" {
+			continue
+		}
+
+		key, err := acct.key()
+		if err != nil {
+			return nil, fmt.Errorf("bad key for account %s: %w", name, err)
+		}
+
+		state.Accounts().AddOrUpdate(&accounts.Account{
+			Name:    c.Network + "-" + name,
+			Address: acct.Address,
+			Key:     key,
+		})
+	}
+
+	return state, nil
+}
+
+func (a *AccountConfig) key() (accounts.Key, error) {
+	if a.Key != nil {
+		return a.Key, nil
+	}
+
+	opts := KeyOptions{Index: a.KeyIndex}
+	if a.SigAlgo != "" {
+		if opts.SigAlgo = crypto.StringToSignatureAlgorithm(a.SigAlgo); opts.SigAlgo == crypto.UnknownSignatureAlgorithm {
+			return nil, fmt.Errorf("unknown signature algorithm %s", a.SigAlgo)
+		}
+	}
+	if a.HashAlgo != "" {
+		if opts.HashAlgo = crypto.StringToHashAlgorithm(a.HashAlgo); opts.HashAlgo == crypto.UnknownHashAlgorithm {
+			return nil, fmt.Errorf("unknown hash algorithm %s", a.HashAlgo)
+		}
+	}
+
+	switch {
+	case a.PrivateKey != "":
+		return NewInMemoryKey(a.PrivateKey, opts)
+	case a.KeyFile != "":
+		return NewFileKey(a.KeyFile, opts)
+	default:
+		publicKey, err := crypto.DecodePublicKeyHex(opts.sigAlgo(), strings.TrimPrefix(a.PublicKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("bad remote signer public key: %w", err)
+		}
+		return NewRemoteKey(a.SignerURL, publicKey, opts, nil), nil
+	}
+}
+
+// NewConnector creates a new Splash Connector for the network described by the configuration.
+func NewConnector(cfg *Config) (*splash.Connector, error) {
+	state, err := cfg.State()
+	if err != nil {
+		return nil, err
+	}
+
+	networkDef, err := state.Networks().ByName(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	gw, err := gateway.NewGrpcGateway(*networkDef)
+	if err != nil {
+		return nil, err
+	}
 
-	key, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, keyStr)
+	grpcClient, err := dialGrpcClient(cfg.Host)
 	if err != nil {
-		return acct, err
+		return nil, err
 	}
 
-	acct.Name = "Sequel"
-	acct.Address = flow.HexToAddress(addrStr)
-	acct.Key = accounts.NewHexKeyFromPrivateKey(0, crypto.SHA3_256, key)
+	logger := splash.NewZeroLogger()
 
-	return acct, nil
+	return &splash.Connector{
+		State:                        state,
+		Services:                     flowkit.NewFlowkit(state, *networkDef, gw, logger),
+		GRPCClient:                   grpcClient,
+		Logger:                       logger,
+		PrependNetworkToAccountNames: true,
+		Network:                      cfg.Network,
+	}, nil
 }
@@ -1,9 +1,14 @@
 package iinft_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
 	. "github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -11,4 +16,138 @@ func TestLoadFlowKitAccount(t *testing.T) {
 	acct, err := LoadFlowKitAccount("f669cb8d41ce0c74", "80025f0d1f2fd1ba0e18f447681fdd6a68a62ea86c2c2fefa811df086d40db3c")
 	require.NoError(t, err)
 	require.NotEmpty(t, acct)
+
+	key, err := NewInMemoryKey("80025f0d1f2fd1ba0e18f447681fdd6a68a62ea86c2c2fefa811df086d40db3c", KeyOptions{
+		Index:    2,
+		SigAlgo:  crypto.ECDSA_secp256k1,
+		HashAlgo: crypto.SHA2_256,
+	})
+	require.NoError(t, err)
+
+	acct = LoadFlowKitAccountWithKey("sequel-admin", "f669cb8d41ce0c74", key)
+	assert.Equal(t, "sequel-admin", acct.Name)
+	assert.Equal(t, flow.HexToAddress("f669cb8d41ce0c74"), acct.Address)
+	assert.Equal(t, uint32(2), acct.Key.Index())
+	assert.Equal(t, crypto.ECDSA_secp256k1, acct.Key.SigAlgo())
+	assert.Equal(t, crypto.SHA2_256, acct.Key.HashAlgo())
+}
+
+func TestEmbeddedConfig(t *testing.T) {
+	cfg, err := EmbeddedConfig("mainnet")
+	require.NoError(t, err)
+
+	assert.Equal(t, "mainnet", cfg.Network)
+	assert.Equal(t, "access.mainnet.nodes.onflow.org:9000", cfg.Host)
+	assert.Equal(t, flow.HexToAddress("9a02a1d17295f3e7"), cfg.Contracts["SequelMarketplace"])
+	assert.Equal(t, flow.HexToAddress("1654653399040a61"), cfg.Contracts["FlowToken"])
+	require.Contains(t, cfg.Accounts, "sequel-admin")
+	assert.Equal(t, flow.HexToAddress("9a02a1d17295f3e7"), cfg.Accounts["sequel-admin"].Address)
+	assert.Empty(t, cfg.Accounts["sequel-admin"].PrivateKey)
+
+	se, err := NewTemplateEngineWithConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, flow.HexToAddress("9a02a1d17295f3e7"), se.ContractAddress("DigitalArt"))
+
+	cfg, err = EmbeddedConfig("emulator")
+	require.NoError(t, err)
+	require.Contains(t, cfg.Accounts, "sequel-admin")
+	assert.NotEmpty(t, cfg.Accounts["sequel-admin"].PrivateKey)
+
+	client, err := NewConnector(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, flow.HexToAddress("179b6b1cb6755e31"), client.Account("sequel-admin").Address)
+
+	_, err = EmbeddedConfig("previewnet")
+	require.Error(t, err)
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	t.Setenv("TEST_FLOW_NETWORK", "testnet")
+	t.Setenv("TEST_FLOW_HOST", "access.devnet.nodes.onflow.org:9000")
+	t.Setenv("TEST_FLOW_CONTRACT_SEQUELMARKETPLACE", "0xfdf325e9204fc94a")
+	t.Setenv("TEST_FLOW_CONTRACT_MyToken", "0x28cfd058c66054a5")
+	t.Setenv("TEST_FLOW_ACCOUNT_SEQUEL_ADMIN_ADDRESS", "0xfdf325e9204fc94a")
+	t.Setenv("TEST_FLOW_ACCOUNT_SEQUEL_ADMIN_KEY", "80025f0d1f2fd1ba0e18f447681fdd6a68a62ea86c2c2fefa811df086d40db3c")
+	t.Setenv("TEST_FLOW_ACCOUNT_SEQUEL_ADMIN_KEY_INDEX", "2")
+
+	cfg, err := ConfigFromEnv("TEST_FLOW_")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Config{
+		Network: "testnet",
+		Host:    "access.devnet.nodes.onflow.org:9000",
+		Contracts: map[string]flow.Address{
+			"SequelMarketplace": flow.HexToAddress("fdf325e9204fc94a"),
+			"MyToken":           flow.HexToAddress("28cfd058c66054a5"),
+		},
+		Accounts: map[string]*AccountConfig{
+			"sequel-admin": {
+				Address:    flow.HexToAddress("fdf325e9204fc94a"),
+				PrivateKey: "80025f0d1f2fd1ba0e18f447681fdd6a68a62ea86c2c2fefa811df086d40db3c",
+				KeyIndex:   2,
+			},
+		},
+	}, cfg)
+
+	state, err := cfg.State()
+	require.NoError(t, err)
+	acct, err := state.Accounts().ByName("testnet-sequel-admin")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), acct.Key.Index())
+
+	// environment variables override the embedded configuration
+	cfg, err = EmbeddedConfig("testnet")
+	require.NoError(t, err)
+	require.NoError(t, cfg.ApplyEnv("TEST_FLOW_"))
+	assert.Equal(t, flow.HexToAddress("fdf325e9204fc94a"), cfg.Contracts["SequelMarketplace"])
+	assert.NotEmpty(t, cfg.Accounts["sequel-admin"].PrivateKey)
+
+	t.Setenv("TEST_FLOW_ACCOUNT_SEQUEL_ADMIN_KEY_INDEX", "x")
+	_, err = ConfigFromEnv("TEST_FLOW_")
+	require.Error(t, err)
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flow.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+network: testnet
+host: access.devnet.nodes.onflow.org:9000
+contracts:
+  SequelMarketplace: "0xfdf325e9204fc94a"
+accounts:
+  sequel-admin:
+    address: "0xfdf325e9204fc94a"
+    privateKey: "80025f0d1f2fd1ba0e18f447681fdd6a68a62ea86c2c2fefa811df086d40db3c"
+    hashAlgo: SHA2_256
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "testnet", cfg.Network)
+	assert.Equal(t, flow.HexToAddress("fdf325e9204fc94a"), cfg.Contracts["SequelMarketplace"])
+	assert.Equal(t, "SHA2_256", cfg.Accounts["sequel-admin"].HashAlgo)
+
+	_, err = cfg.State()
+	require.NoError(t, err)
+
+	privateKey, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, "80025f0d1f2fd1ba0e18f447681fdd6a68a62ea86c2c2fefa811df086d40db3c")
+	require.NoError(t, err)
+	cfg.Accounts["sequel-platform"] = &AccountConfig{
+		Address:   flow.HexToAddress("28cfd058c66054a5"),
+		SignerURL: "http://localhost:8080/sign",
+		PublicKey: privateKey.PublicKey().String(),
+	}
+	state, err := cfg.State()
+	require.NoError(t, err)
+	acct, err := state.Accounts().ByName("testnet-sequel-platform")
+	require.NoError(t, err)
+	assert.Equal(t, KeyTypeRemote, acct.Key.Type())
+
+	cfg.Accounts["sequel-admin"].HashAlgo = "MD5"
+	_, err = cfg.State()
+	require.Error(t, err)
+
+	cfg.Host = ""
+	_, err = NewGrpcClientWithConfig(cfg)
+	require.ErrorIs(t, err, ErrNoHost)
 }
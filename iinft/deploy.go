@@ -0,0 +1,80 @@
+package iinft
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/piprate/splash"
+)
+
+// OptionalContracts lists Sequel contracts that aren't in the default deployments of flow.json.
+// Use DeployOptionalContractsE to deploy them.
+var OptionalContracts = []string{"SequelOffers", "SequelAuctions"}
+
+// InitializeContractsE deploys the contracts listed in the deployment section of the client's
+// configuration. If adminFlowDeposit isn't empty, the admin account is funded with
+// adminFlowDeposit FLOW from the service account first.
+func InitializeContractsE(ctx context.Context, client *splash.Connector, adminAccountName, adminFlowDeposit string) error {
+	if err := fundAdminAccount(ctx, client, adminAccountName, adminFlowDeposit); err != nil {
+		return err
+	}
+
+	return client.InitializeContractsE(ctx)
+}
+
+// DeployOptionalContractsE adds the given optional contracts (see OptionalContracts) to the admin
+// account's deployment for the client's network and deploys them. The contracts they import
+// must be deployed already (see InitializeContractsE). Optional contracts exceed the default
+// storage capacity of a new account, so the admin account is funded with adminFlowDeposit FLOW
+// from the service account first. If adminFlowDeposit is empty, the admin account must already
+// have enough FLOW.
+//
+// Template engines created before this call don't know the addresses of the new contracts.
+func DeployOptionalContractsE(ctx context.Context, client *splash.Connector, adminAccountName, adminFlowDeposit string, contractNames ...string) error {
+	for _, name := range contractNames {
+		if !slices.Contains(OptionalContracts, name) {
+			return fmt.Errorf("%s isn't an optional Sequel contract", name)
+		}
+	}
+
+	if err := fundAdminAccount(ctx, client, adminAccountName, adminFlowDeposit); err != nil {
+		return err
+	}
+
+	network := client.Services.Network().Name
+	adminAcct := client.Account(adminAccountName)
+
+	deployments := client.State.Deployments()
+	deployment := deployments.ByAccountAndNetwork(adminAcct.Name, network)
+	if deployment == nil {
+		deployment = &config.Deployment{Network: network, Account: adminAcct.Name}
+	}
+	for _, name := range contractNames {
+		deployment.AddContract(config.ContractDeployment{Name: name})
+	}
+	deployments.AddOrUpdate(*deployment)
+
+	// contracts that are deployed already are skipped
+	return client.InitializeContractsE(ctx)
+}
+
+func fundAdminAccount(ctx context.Context, client *splash.Connector, adminAccountName, adminFlowDeposit string) error {
+	if adminFlowDeposit == "" {
+		return nil
+	}
+
+	se, err := NewTemplateEngine(client)
+	if err != nil {
+		return err
+	}
+
+	_, err = se.NewTransaction("account_fund_flow").
+		Argument(cadence.NewAddress(client.Account(adminAccountName).Address)).
+		UFix64Argument(adminFlowDeposit).
+		SignProposeAndPayAsService().
+		RunE(ctx)
+	return err
+}
@@ -0,0 +1,46 @@
+package iinft_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	. "github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployOptionalContractsE(t *testing.T) {
+	client, err := NewInMemoryConnectorEmbedded(false)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = client.CreateAccountsE(ctx, "emulator-account")
+	require.NoError(t, err)
+
+	err = client.InitializeContractsE(ctx)
+	require.NoError(t, err)
+
+	se, err := NewTemplateEngine(client)
+	require.NoError(t, err)
+	assert.Equal(t, flow.EmptyAddress, se.ContractAddress("SequelOffers"))
+
+	err = DeployOptionalContractsE(ctx, client, "sequel-admin", "10.0", "SequelMarketplace")
+	require.Error(t, err)
+
+	err = DeployOptionalContractsE(ctx, client, "sequel-admin", "10.0", OptionalContracts...)
+	require.NoError(t, err)
+
+	se, err = NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	adminAddr := client.Account("sequel-admin").Address
+	assert.Equal(t, adminAddr, se.ContractAddress("SequelOffers"))
+	assert.Equal(t, adminAddr, se.ContractAddress("SequelAuctions"))
+
+	acct, err := client.Services.GetAccount(ctx, adminAddr)
+	require.NoError(t, err)
+	assert.Contains(t, acct.Contracts, "SequelOffers")
+	assert.Contains(t, acct.Contracts, "SequelAuctions")
+}
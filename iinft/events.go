@@ -0,0 +1,1019 @@
+package iinft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/splash"
+)
+
+var ErrUnknownEvent = errors.New("unknown event type")
+
+type (
+	// MintedEvent is emitted by DigitalArt contract when a new edition is minted.
+	MintedEvent struct {
+		ID      uint64
+		Asset   string
+		Edition uint64
+		// ModID links the mint-on-demand request with the Marketplace database.
+		// Zero, if the token wasn't minted on demand.
+		ModID uint64
+	}
+
+	// DepositEvent is emitted by DigitalArt contract when an NFT is deposited into a collection.
+	DepositEvent struct {
+		ID uint64
+		To *flow.Address
+	}
+
+	// WithdrawEvent is emitted by DigitalArt contract when an NFT is withdrawn from a collection.
+	WithdrawEvent struct {
+		ID   uint64
+		From *flow.Address
+	}
+
+	// MasterUpdatedEvent is emitted by DigitalArt contract when the metadata or Evergreen profile
+	// of a master is updated before the first edition is minted.
+	MasterUpdatedEvent struct {
+		Asset string
+	}
+
+	// MasterPausedEvent is emitted by DigitalArt contract when minting from a master is paused.
+	MasterPausedEvent struct {
+		Asset string
+	}
+
+	// MasterResumedEvent is emitted by DigitalArt contract when minting from a paused master is resumed.
+	MasterResumedEvent struct {
+		Asset string
+	}
+
+	// MasterClosedEvent is emitted by DigitalArt contract when a master is closed
+	// before all its editions are minted.
+	MasterClosedEvent struct {
+		Asset            string
+		UnmintedEditions uint64
+	}
+
+	// TokenListedEvent is emitted by SequelMarketplace contract when a token is listed for sale.
+	TokenListedEvent struct {
+		StorefrontAddress flow.Address
+		ListingID         uint64
+		NFTType           string
+		NFTID             uint64
+		PaymentVaultType  string
+		Price             evergreen.UFix64
+		Payments          []*evergreen.Payment
+		Asset             string
+		MetadataLink      *string
+		// Prices lists all accepted currencies, if the token was listed in several currencies.
+		// The first item describes this listing. Prices is empty for events emitted by
+		// earlier versions of SequelMarketplace contract.
+		Prices []*ListingPrice
+	}
+
+	// ListingPrice mirrors SequelMarketplace.ListingPrice structure.
+	ListingPrice struct {
+		// ListingID is the ID of the storefront listing that accepts this currency.
+		ListingID        uint64
+		PaymentVaultType string
+		Price            evergreen.UFix64
+	}
+
+	// TokenSoldEvent is emitted by SequelMarketplace contract when a listed token is sold.
+	TokenSoldEvent struct {
+		StorefrontAddress flow.Address
+		ListingID         uint64
+		NFTType           string
+		NFTID             uint64
+		PaymentVaultType  string
+		Price             evergreen.UFix64
+		BuyerAddress      flow.Address
+		MetadataLink      *string
+	}
+
+	// TokenWithdrawnEvent is emitted by SequelMarketplace contract when a listing is withdrawn.
+	TokenWithdrawnEvent struct {
+		StorefrontAddress flow.Address
+		ListingID         uint64
+		NFTType           string
+		NFTID             uint64
+		VaultType         string
+		Price             evergreen.UFix64
+	}
+
+	// ListingCleanedUpEvent is emitted by SequelMarketplace contract when a stale listing is removed.
+	ListingCleanedUpEvent struct {
+		StorefrontAddress flow.Address
+		ListingID         uint64
+		NFTType           string
+		NFTID             uint64
+		// Reason is one of StaleReason* constants.
+		Reason string
+	}
+
+	// OfferMadeEvent is emitted by SequelOffers contract when a buyer escrows funds
+	// for a token with the given ID or any edition of the given asset.
+	OfferMadeEvent struct {
+		BuyerAddress     flow.Address
+		OfferID          uint64
+		NFTType          string
+		NFTID            *uint64
+		Asset            *string
+		PaymentVaultType string
+		Price            evergreen.UFix64
+	}
+
+	// OfferAcceptedEvent is emitted by SequelOffers contract when the token's owner accepts an offer.
+	OfferAcceptedEvent struct {
+		BuyerAddress     flow.Address
+		OfferID          uint64
+		NFTType          string
+		NFTID            uint64
+		Asset            string
+		PaymentVaultType string
+		Price            evergreen.UFix64
+		SellerAddress    flow.Address
+		Payments         []*evergreen.Payment
+	}
+
+	// OfferCancelledEvent is emitted by SequelOffers contract when the buyer cancels an offer.
+	OfferCancelledEvent struct {
+		BuyerAddress flow.Address
+		OfferID      uint64
+	}
+
+	// AuctionCreatedEvent is emitted by SequelAuctions contract when a token is escrowed for sale in an auction.
+	AuctionCreatedEvent struct {
+		AuctionID        uint64
+		SellerAddress    flow.Address
+		Kind             AuctionKind
+		NFTType          string
+		NFTID            uint64
+		Asset            string
+		PaymentVaultType string
+		StartPrice       evergreen.UFix64
+		ReservePrice     evergreen.UFix64
+		StartTime        time.Time
+		EndTime          time.Time
+	}
+
+	// BidPlacedEvent is emitted by SequelAuctions contract when a bid is accepted.
+	// EndTime reflects any extension caused by the bid.
+	BidPlacedEvent struct {
+		AuctionID     uint64
+		SellerAddress flow.Address
+		BidderAddress flow.Address
+		Amount        evergreen.UFix64
+		EndTime       time.Time
+	}
+
+	// AuctionSettledEvent is emitted by SequelAuctions contract when an auction is concluded.
+	// If the token wasn't sold, WinnerAddress and Price are nil.
+	AuctionSettledEvent struct {
+		AuctionID     uint64
+		SellerAddress flow.Address
+		NFTID         uint64
+		Asset         string
+		WinnerAddress *flow.Address
+		Price         *evergreen.UFix64
+		Payments      []*evergreen.Payment
+	}
+
+	// AuctionCancelledEvent is emitted by SequelAuctions contract when the seller cancels an auction.
+	AuctionCancelledEvent struct {
+		AuctionID     uint64
+		SellerAddress flow.Address
+	}
+
+	// EventDecoder converts raw Flow events emitted by DigitalArt, SequelMarketplace,
+	// SequelOffers and SequelAuctions contracts into typed event structures. Event type IDs are resolved
+	// using the contract addresses of the given network.
+	EventDecoder struct {
+		decoders map[string]func(cadence.Event) (any, error)
+	}
+)
+
+// EventTypeID returns a fully qualified event type ID, i.e. A.179b6b1cb6755e31.DigitalArt.Minted
+func EventTypeID(contractAddr flow.Address, contractName, eventName string) string {
+	return fmt.Sprintf("A.%s.%s.%s", contractAddr.Hex(), contractName, eventName)
+}
+
+// NewEventDecoder creates an event decoder for the network the template engine is connected to.
+func NewEventDecoder(se *splash.TemplateEngine) *EventDecoder {
+	digitalArtAddr := se.ContractAddress("DigitalArt")
+	marketplaceAddr := se.ContractAddress("SequelMarketplace")
+
+	d := &EventDecoder{
+		decoders: map[string]func(cadence.Event) (any, error){
+			EventTypeID(digitalArtAddr, "DigitalArt", "Minted"): func(ev cadence.Event) (any, error) {
+				return MintedEventFromCadence(ev)
+			},
+			EventTypeID(digitalArtAddr, "DigitalArt", "Deposit"): func(ev cadence.Event) (any, error) {
+				return DepositEventFromCadence(ev)
+			},
+			EventTypeID(digitalArtAddr, "DigitalArt", "Withdraw"): func(ev cadence.Event) (any, error) {
+				return WithdrawEventFromCadence(ev)
+			},
+			EventTypeID(digitalArtAddr, "DigitalArt", "MasterUpdated"): func(ev cadence.Event) (any, error) {
+				return MasterUpdatedEventFromCadence(ev)
+			},
+			EventTypeID(digitalArtAddr, "DigitalArt", "MasterPaused"): func(ev cadence.Event) (any, error) {
+				return MasterPausedEventFromCadence(ev)
+			},
+			EventTypeID(digitalArtAddr, "DigitalArt", "MasterResumed"): func(ev cadence.Event) (any, error) {
+				return MasterResumedEventFromCadence(ev)
+			},
+			EventTypeID(digitalArtAddr, "DigitalArt", "MasterClosed"): func(ev cadence.Event) (any, error) {
+				return MasterClosedEventFromCadence(ev)
+			},
+			EventTypeID(marketplaceAddr, "SequelMarketplace", "TokenListed"): func(ev cadence.Event) (any, error) {
+				return TokenListedEventFromCadence(ev)
+			},
+			EventTypeID(marketplaceAddr, "SequelMarketplace", "TokenSold"): func(ev cadence.Event) (any, error) {
+				return TokenSoldEventFromCadence(ev)
+			},
+			EventTypeID(marketplaceAddr, "SequelMarketplace", "TokenWithdrawn"): func(ev cadence.Event) (any, error) {
+				return TokenWithdrawnEventFromCadence(ev)
+			},
+			EventTypeID(marketplaceAddr, "SequelMarketplace", "ListingCleanedUp"): func(ev cadence.Event) (any, error) {
+				return ListingCleanedUpEventFromCadence(ev)
+			},
+		},
+	}
+
+	// SequelOffers isn't deployed on every network
+	if offersAddr := se.ContractAddress("SequelOffers"); offersAddr != flow.EmptyAddress {
+		d.decoders[EventTypeID(offersAddr, "SequelOffers", "OfferMade")] = func(ev cadence.Event) (any, error) {
+			return OfferMadeEventFromCadence(ev)
+		}
+		d.decoders[EventTypeID(offersAddr, "SequelOffers", "OfferAccepted")] = func(ev cadence.Event) (any, error) {
+			return OfferAcceptedEventFromCadence(ev)
+		}
+		d.decoders[EventTypeID(offersAddr, "SequelOffers", "OfferCancelled")] = func(ev cadence.Event) (any, error) {
+			return OfferCancelledEventFromCadence(ev)
+		}
+	}
+
+	// SequelAuctions isn't deployed on every network
+	if auctionsAddr := se.ContractAddress("SequelAuctions"); auctionsAddr != flow.EmptyAddress {
+		d.decoders[EventTypeID(auctionsAddr, "SequelAuctions", "AuctionCreated")] = func(ev cadence.Event) (any, error) {
+			return AuctionCreatedEventFromCadence(ev)
+		}
+		d.decoders[EventTypeID(auctionsAddr, "SequelAuctions", "BidPlaced")] = func(ev cadence.Event) (any, error) {
+			return BidPlacedEventFromCadence(ev)
+		}
+		d.decoders[EventTypeID(auctionsAddr, "SequelAuctions", "AuctionSettled")] = func(ev cadence.Event) (any, error) {
+			return AuctionSettledEventFromCadence(ev)
+		}
+		d.decoders[EventTypeID(auctionsAddr, "SequelAuctions", "AuctionCancelled")] = func(ev cadence.Event) (any, error) {
+			return AuctionCancelledEventFromCadence(ev)
+		}
+	}
+
+	return d
+}
+
+// EventTypes returns a sorted list of event type IDs supported by the decoder.
+func (d *EventDecoder) EventTypes() []string {
+	res := make([]string, 0, len(d.decoders))
+	for eventType := range d.decoders {
+		res = append(res, eventType)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// Decode converts the given Flow event into one of the typed event structures
+// (i.e. *MintedEvent). It returns ErrUnknownEvent if the event type isn't supported.
+func (d *EventDecoder) Decode(ev flow.Event) (any, error) {
+	decoder, found := d.decoders[ev.Type]
+	if !found {
+		return nil, ErrUnknownEvent
+	}
+
+	return decoder(ev.Value)
+}
+
+// MintedEvents decodes all DigitalArt.Minted events in the given list.
+func (d *EventDecoder) MintedEvents(events []flow.Event) ([]*MintedEvent, error) {
+	return decodeAll[*MintedEvent](d, events)
+}
+
+// TokenListedEvents decodes all SequelMarketplace.TokenListed events in the given list.
+func (d *EventDecoder) TokenListedEvents(events []flow.Event) ([]*TokenListedEvent, error) {
+	return decodeAll[*TokenListedEvent](d, events)
+}
+
+// TokenSoldEvents decodes all SequelMarketplace.TokenSold events in the given list.
+func (d *EventDecoder) TokenSoldEvents(events []flow.Event) ([]*TokenSoldEvent, error) {
+	return decodeAll[*TokenSoldEvent](d, events)
+}
+
+// ListingCleanedUpEvents decodes all SequelMarketplace.ListingCleanedUp events in the given list.
+func (d *EventDecoder) ListingCleanedUpEvents(events []flow.Event) ([]*ListingCleanedUpEvent, error) {
+	return decodeAll[*ListingCleanedUpEvent](d, events)
+}
+
+// OfferMadeEvents decodes all SequelOffers.OfferMade events in the given list.
+func (d *EventDecoder) OfferMadeEvents(events []flow.Event) ([]*OfferMadeEvent, error) {
+	return decodeAll[*OfferMadeEvent](d, events)
+}
+
+// OfferAcceptedEvents decodes all SequelOffers.OfferAccepted events in the given list.
+func (d *EventDecoder) OfferAcceptedEvents(events []flow.Event) ([]*OfferAcceptedEvent, error) {
+	return decodeAll[*OfferAcceptedEvent](d, events)
+}
+
+// AuctionCreatedEvents decodes all SequelAuctions.AuctionCreated events in the given list.
+func (d *EventDecoder) AuctionCreatedEvents(events []flow.Event) ([]*AuctionCreatedEvent, error) {
+	return decodeAll[*AuctionCreatedEvent](d, events)
+}
+
+// BidPlacedEvents decodes all SequelAuctions.BidPlaced events in the given list.
+func (d *EventDecoder) BidPlacedEvents(events []flow.Event) ([]*BidPlacedEvent, error) {
+	return decodeAll[*BidPlacedEvent](d, events)
+}
+
+// AuctionSettledEvents decodes all SequelAuctions.AuctionSettled events in the given list.
+func (d *EventDecoder) AuctionSettledEvents(events []flow.Event) ([]*AuctionSettledEvent, error) {
+	return decodeAll[*AuctionSettledEvent](d, events)
+}
+
+func decodeAll[T any](d *EventDecoder, events []flow.Event) ([]T, error) {
+	var res []T
+	for _, ev := range events {
+		val, err := d.Decode(ev)
+		if err != nil {
+			if errors.Is(err, ErrUnknownEvent) {
+				continue
+			}
+			return nil, err
+		}
+		if typedVal, ok := val.(T); ok {
+			res = append(res, typedVal)
+		}
+	}
+
+	return res, nil
+}
+
+func MintedEventFromCadence(val cadence.Event) (*MintedEvent, error) {
+	fields, err := eventFields(val, "DigitalArt.Minted")
+	if err != nil {
+		return nil, err
+	}
+
+	var res MintedEvent
+	if res.ID, err = uint64Field(fields, "id"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.Edition, err = uint64Field(fields, "edition"); err != nil {
+		return nil, err
+	}
+	if res.ModID, err = uint64Field(fields, "modID"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func DepositEventFromCadence(val cadence.Event) (*DepositEvent, error) {
+	fields, err := eventFields(val, "DigitalArt.Deposit")
+	if err != nil {
+		return nil, err
+	}
+
+	var res DepositEvent
+	if res.ID, err = uint64Field(fields, "id"); err != nil {
+		return nil, err
+	}
+	if res.To, err = optionalAddressField(fields, "to"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func WithdrawEventFromCadence(val cadence.Event) (*WithdrawEvent, error) {
+	fields, err := eventFields(val, "DigitalArt.Withdraw")
+	if err != nil {
+		return nil, err
+	}
+
+	var res WithdrawEvent
+	if res.ID, err = uint64Field(fields, "id"); err != nil {
+		return nil, err
+	}
+	if res.From, err = optionalAddressField(fields, "from"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func MasterUpdatedEventFromCadence(val cadence.Event) (*MasterUpdatedEvent, error) {
+	fields, err := eventFields(val, "DigitalArt.MasterUpdated")
+	if err != nil {
+		return nil, err
+	}
+
+	var res MasterUpdatedEvent
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func MasterPausedEventFromCadence(val cadence.Event) (*MasterPausedEvent, error) {
+	fields, err := eventFields(val, "DigitalArt.MasterPaused")
+	if err != nil {
+		return nil, err
+	}
+
+	var res MasterPausedEvent
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func MasterResumedEventFromCadence(val cadence.Event) (*MasterResumedEvent, error) {
+	fields, err := eventFields(val, "DigitalArt.MasterResumed")
+	if err != nil {
+		return nil, err
+	}
+
+	var res MasterResumedEvent
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func MasterClosedEventFromCadence(val cadence.Event) (*MasterClosedEvent, error) {
+	fields, err := eventFields(val, "DigitalArt.MasterClosed")
+	if err != nil {
+		return nil, err
+	}
+
+	var res MasterClosedEvent
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.UnmintedEditions, err = uint64Field(fields, "unmintedEditions"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func TokenListedEventFromCadence(val cadence.Event) (*TokenListedEvent, error) {
+	fields, err := eventFields(val, "SequelMarketplace.TokenListed")
+	if err != nil {
+		return nil, err
+	}
+
+	var res TokenListedEvent
+	if res.StorefrontAddress, err = addressField(fields, "storefrontAddress"); err != nil {
+		return nil, err
+	}
+	if res.ListingID, err = uint64Field(fields, "listingID"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = stringField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = stringField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.MetadataLink, err = optionalStringField(fields, "metadataLink"); err != nil {
+		return nil, err
+	}
+
+	paymentsArray, ok := fields["payments"].(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad payments value")
+	}
+	res.Payments = make([]*evergreen.Payment, len(paymentsArray.Values))
+	for i, paymentVal := range paymentsArray.Values {
+		if res.Payments[i], err = PaymentFromCadence(paymentVal); err != nil {
+			return nil, err
+		}
+	}
+
+	if pricesVal, found := fields["prices"]; found {
+		pricesArray, ok := pricesVal.(cadence.Array)
+		if !ok {
+			return nil, errors.New("bad prices value")
+		}
+		res.Prices = make([]*ListingPrice, len(pricesArray.Values))
+		for i, priceVal := range pricesArray.Values {
+			if res.Prices[i], err = ListingPriceFromCadence(priceVal); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &res, nil
+}
+
+// ListingPriceFromCadence decodes SequelMarketplace.ListingPrice value.
+func ListingPriceFromCadence(val cadence.Value) (*ListingPrice, error) {
+	valStruct, ok := val.(cadence.Struct)
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "SequelMarketplace.ListingPrice" {
+		return nil, errors.New("not a SequelMarketplace.ListingPrice value")
+	}
+
+	fields, err := structFields(valStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var res ListingPrice
+	if res.ListingID, err = uint64Field(fields, "listingID"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = stringField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func TokenSoldEventFromCadence(val cadence.Event) (*TokenSoldEvent, error) {
+	fields, err := eventFields(val, "SequelMarketplace.TokenSold")
+	if err != nil {
+		return nil, err
+	}
+
+	var res TokenSoldEvent
+	if res.StorefrontAddress, err = addressField(fields, "storefrontAddress"); err != nil {
+		return nil, err
+	}
+	if res.ListingID, err = uint64Field(fields, "listingID"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = stringField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = stringField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+	if res.BuyerAddress, err = addressField(fields, "buyerAddress"); err != nil {
+		return nil, err
+	}
+	if res.MetadataLink, err = optionalStringField(fields, "metadataLink"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func TokenWithdrawnEventFromCadence(val cadence.Event) (*TokenWithdrawnEvent, error) {
+	fields, err := eventFields(val, "SequelMarketplace.TokenWithdrawn")
+	if err != nil {
+		return nil, err
+	}
+
+	var res TokenWithdrawnEvent
+	if res.StorefrontAddress, err = addressField(fields, "storefrontAddress"); err != nil {
+		return nil, err
+	}
+	if res.ListingID, err = uint64Field(fields, "listingID"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = stringField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.VaultType, err = stringField(fields, "vaultType"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func ListingCleanedUpEventFromCadence(val cadence.Event) (*ListingCleanedUpEvent, error) {
+	fields, err := eventFields(val, "SequelMarketplace.ListingCleanedUp")
+	if err != nil {
+		return nil, err
+	}
+
+	var res ListingCleanedUpEvent
+	if res.StorefrontAddress, err = addressField(fields, "storefrontAddress"); err != nil {
+		return nil, err
+	}
+	if res.ListingID, err = uint64Field(fields, "listingID"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = stringField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.Reason, err = stringField(fields, "reason"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func OfferMadeEventFromCadence(val cadence.Event) (*OfferMadeEvent, error) {
+	fields, err := eventFields(val, "SequelOffers.OfferMade")
+	if err != nil {
+		return nil, err
+	}
+
+	var res OfferMadeEvent
+	if res.BuyerAddress, err = addressField(fields, "buyerAddress"); err != nil {
+		return nil, err
+	}
+	if res.OfferID, err = uint64Field(fields, "offerID"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = stringField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = optionalUInt64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = optionalStringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = stringField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func OfferAcceptedEventFromCadence(val cadence.Event) (*OfferAcceptedEvent, error) {
+	fields, err := eventFields(val, "SequelOffers.OfferAccepted")
+	if err != nil {
+		return nil, err
+	}
+
+	var res OfferAcceptedEvent
+	if res.BuyerAddress, err = addressField(fields, "buyerAddress"); err != nil {
+		return nil, err
+	}
+	if res.OfferID, err = uint64Field(fields, "offerID"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = stringField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = stringField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+	if res.SellerAddress, err = addressField(fields, "sellerAddress"); err != nil {
+		return nil, err
+	}
+
+	paymentsArray, ok := fields["payments"].(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad payments value")
+	}
+	res.Payments = make([]*evergreen.Payment, len(paymentsArray.Values))
+	for i, paymentVal := range paymentsArray.Values {
+		if res.Payments[i], err = PaymentFromCadence(paymentVal); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+func OfferCancelledEventFromCadence(val cadence.Event) (*OfferCancelledEvent, error) {
+	fields, err := eventFields(val, "SequelOffers.OfferCancelled")
+	if err != nil {
+		return nil, err
+	}
+
+	var res OfferCancelledEvent
+	if res.BuyerAddress, err = addressField(fields, "buyerAddress"); err != nil {
+		return nil, err
+	}
+	if res.OfferID, err = uint64Field(fields, "offerID"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func AuctionCreatedEventFromCadence(val cadence.Event) (*AuctionCreatedEvent, error) {
+	fields, err := eventFields(val, "SequelAuctions.AuctionCreated")
+	if err != nil {
+		return nil, err
+	}
+
+	var res AuctionCreatedEvent
+	if res.AuctionID, err = uint64Field(fields, "auctionID"); err != nil {
+		return nil, err
+	}
+	if res.SellerAddress, err = addressField(fields, "sellerAddress"); err != nil {
+		return nil, err
+	}
+	kind, ok := fields["kind"].(cadence.UInt8)
+	if !ok {
+		return nil, errors.New("bad kind value")
+	}
+	res.Kind = AuctionKind(kind)
+	if res.NFTType, err = stringField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = stringField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.StartPrice, err = ufix64Field(fields, "startPrice"); err != nil {
+		return nil, err
+	}
+	if res.ReservePrice, err = ufix64Field(fields, "reservePrice"); err != nil {
+		return nil, err
+	}
+	if res.StartTime, err = timeField(fields, "startTime"); err != nil {
+		return nil, err
+	}
+	if res.EndTime, err = timeField(fields, "endTime"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func BidPlacedEventFromCadence(val cadence.Event) (*BidPlacedEvent, error) {
+	fields, err := eventFields(val, "SequelAuctions.BidPlaced")
+	if err != nil {
+		return nil, err
+	}
+
+	var res BidPlacedEvent
+	if res.AuctionID, err = uint64Field(fields, "auctionID"); err != nil {
+		return nil, err
+	}
+	if res.SellerAddress, err = addressField(fields, "sellerAddress"); err != nil {
+		return nil, err
+	}
+	if res.BidderAddress, err = addressField(fields, "bidderAddress"); err != nil {
+		return nil, err
+	}
+	if res.Amount, err = ufix64Field(fields, "amount"); err != nil {
+		return nil, err
+	}
+	if res.EndTime, err = timeField(fields, "endTime"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func AuctionSettledEventFromCadence(val cadence.Event) (*AuctionSettledEvent, error) {
+	fields, err := eventFields(val, "SequelAuctions.AuctionSettled")
+	if err != nil {
+		return nil, err
+	}
+
+	var res AuctionSettledEvent
+	if res.AuctionID, err = uint64Field(fields, "auctionID"); err != nil {
+		return nil, err
+	}
+	if res.SellerAddress, err = addressField(fields, "sellerAddress"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = uint64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = stringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.WinnerAddress, err = optionalAddressField(fields, "winnerAddress"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = optionalUFix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+
+	paymentsArray, ok := fields["payments"].(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad payments value")
+	}
+	res.Payments = make([]*evergreen.Payment, len(paymentsArray.Values))
+	for i, paymentVal := range paymentsArray.Values {
+		if res.Payments[i], err = PaymentFromCadence(paymentVal); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+func AuctionCancelledEventFromCadence(val cadence.Event) (*AuctionCancelledEvent, error) {
+	fields, err := eventFields(val, "SequelAuctions.AuctionCancelled")
+	if err != nil {
+		return nil, err
+	}
+
+	var res AuctionCancelledEvent
+	if res.AuctionID, err = uint64Field(fields, "auctionID"); err != nil {
+		return nil, err
+	}
+	if res.SellerAddress, err = addressField(fields, "sellerAddress"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func PaymentFromCadence(val cadence.Value) (*evergreen.Payment, error) {
+	valStruct, ok := val.(cadence.Struct)
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "SequelMarketplace.Payment" {
+		return nil, errors.New("bad Payment value")
+	}
+
+	fields := valStruct.FieldsMappedByName()
+
+	var res evergreen.Payment
+	var err error
+	if res.Role, err = stringField(fields, "role"); err != nil {
+		return nil, err
+	}
+	if res.Receiver, err = addressField(fields, "receiver"); err != nil {
+		return nil, err
+	}
+	if res.Amount, err = ufix64Field(fields, "amount"); err != nil {
+		return nil, err
+	}
+	if res.Rate, err = ufix64Field(fields, "rate"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func eventFields(val cadence.Event, qualifiedIdentifier string) (map[string]cadence.Value, error) {
+	if val.EventType == nil || val.EventType.QualifiedIdentifier != qualifiedIdentifier {
+		return nil, fmt.Errorf("bad %s event", qualifiedIdentifier)
+	}
+
+	return val.FieldsMappedByName(), nil
+}
+
+func stringField(fields map[string]cadence.Value, name string) (string, error) {
+	val, ok := fields[name].(cadence.String)
+	if !ok {
+		return "", fmt.Errorf("bad %s value", name)
+	}
+	return string(val), nil
+}
+
+func uint64Field(fields map[string]cadence.Value, name string) (uint64, error) {
+	val, ok := fields[name].(cadence.UInt64)
+	if !ok {
+		return 0, fmt.Errorf("bad %s value", name)
+	}
+	return uint64(val), nil
+}
+
+func ufix64Field(fields map[string]cadence.Value, name string) (evergreen.UFix64, error) {
+	val, ok := fields[name].(cadence.UFix64)
+	if !ok {
+		return 0, fmt.Errorf("bad %s value", name)
+	}
+	return evergreen.UFix64(val), nil
+}
+
+// timeField decodes a UFix64 Unix timestamp, such as getCurrentBlock().timestamp.
+func timeField(fields map[string]cadence.Value, name string) (time.Time, error) {
+	val, ok := fields[name].(cadence.UFix64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("bad %s value", name)
+	}
+	return UFix64ToTime(evergreen.UFix64(val)), nil
+}
+
+func optionalUFix64Field(fields map[string]cadence.Value, name string) (*evergreen.UFix64, error) {
+	opt, ok := fields[name].(cadence.Optional)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	if opt.Value == nil {
+		return nil, nil
+	}
+	val, ok := opt.Value.(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	v := evergreen.UFix64(val)
+	return &v, nil
+}
+
+func addressField(fields map[string]cadence.Value, name string) (flow.Address, error) {
+	val, ok := fields[name].(cadence.Address)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("bad %s value", name)
+	}
+	return flow.BytesToAddress(val.Bytes()), nil
+}
+
+func optionalAddressField(fields map[string]cadence.Value, name string) (*flow.Address, error) {
+	opt, ok := fields[name].(cadence.Optional)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	if opt.Value == nil {
+		return nil, nil
+	}
+	val, ok := opt.Value.(cadence.Address)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	addr := flow.BytesToAddress(val.Bytes())
+	return &addr, nil
+}
+
+func optionalUInt64Field(fields map[string]cadence.Value, name string) (*uint64, error) {
+	opt, ok := fields[name].(cadence.Optional)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	if opt.Value == nil {
+		return nil, nil
+	}
+	val, ok := opt.Value.(cadence.UInt64)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	v := uint64(val)
+	return &v, nil
+}
+
+func optionalStringField(fields map[string]cadence.Value, name string) (*string, error) {
+	opt, ok := fields[name].(cadence.Optional)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	if opt.Value == nil {
+		return nil, nil
+	}
+	val, ok := opt.Value.(cadence.String)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+	str := string(val)
+	return &str, nil
+}
@@ -0,0 +1,234 @@
+package iinft_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/flow-go-sdk"
+	. "github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sequelAddress = flow.HexToAddress("0x179b6b1cb6755e31")
+	userAddress   = flow.HexToAddress("0xe03daebed8ca0615")
+)
+
+func newTestEvent(qualifiedIdentifier string, fields []cadence.Field, values []cadence.Value) cadence.Event {
+	return cadence.NewEvent(values).WithType(cadence.NewEventType(
+		common.AddressLocation{
+			Address: common.Address(sequelAddress),
+			Name:    common.AddressLocationPrefix,
+		},
+		qualifiedIdentifier,
+		fields,
+		nil,
+	))
+}
+
+func mustUFix64(t *testing.T, s string) cadence.UFix64 {
+	t.Helper()
+
+	v, err := cadence.NewUFix64(s)
+	require.NoError(t, err)
+	return v
+}
+
+func TestEventTypeID(t *testing.T) {
+	assert.Equal(t, "A.179b6b1cb6755e31.DigitalArt.Minted", EventTypeID(sequelAddress, "DigitalArt", "Minted"))
+}
+
+func TestMintedEventFromCadence(t *testing.T) {
+	fields := []cadence.Field{
+		{Identifier: "id", Type: cadence.UInt64Type},
+		{Identifier: "asset", Type: cadence.StringType},
+		{Identifier: "edition", Type: cadence.UInt64Type},
+		{Identifier: "modID", Type: cadence.UInt64Type},
+	}
+
+	ev, err := MintedEventFromCadence(newTestEvent("DigitalArt.Minted", fields, []cadence.Value{
+		cadence.UInt64(12),
+		cadence.String("did:sequel:asset-id"),
+		cadence.UInt64(3),
+		cadence.UInt64(123),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, &MintedEvent{
+		ID:      12,
+		Asset:   "did:sequel:asset-id",
+		Edition: 3,
+		ModID:   123,
+	}, ev)
+
+	// wrong event type
+
+	_, err = MintedEventFromCadence(newTestEvent("DigitalArt.Deposit", fields, []cadence.Value{
+		cadence.UInt64(12),
+		cadence.String("did:sequel:asset-id"),
+		cadence.UInt64(3),
+		cadence.UInt64(123),
+	}))
+	require.Error(t, err)
+
+	// mistyped field
+
+	_, err = MintedEventFromCadence(newTestEvent("DigitalArt.Minted", fields, []cadence.Value{
+		cadence.UInt64(12),
+		cadence.UInt64(1),
+		cadence.UInt64(3),
+		cadence.UInt64(123),
+	}))
+	require.EqualError(t, err, "bad asset value")
+}
+
+func TestDepositEventFromCadence(t *testing.T) {
+	fields := []cadence.Field{
+		{Identifier: "id", Type: cadence.UInt64Type},
+		{Identifier: "to", Type: cadence.NewOptionalType(cadence.AddressType)},
+	}
+
+	ev, err := DepositEventFromCadence(newTestEvent("DigitalArt.Deposit", fields, []cadence.Value{
+		cadence.UInt64(12),
+		cadence.NewOptional(cadence.NewAddress(userAddress)),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), ev.ID)
+	require.NotNil(t, ev.To)
+	assert.Equal(t, userAddress, *ev.To)
+
+	ev, err = DepositEventFromCadence(newTestEvent("DigitalArt.Deposit", fields, []cadence.Value{
+		cadence.UInt64(12),
+		cadence.NewOptional(nil),
+	}))
+	require.NoError(t, err)
+	assert.Nil(t, ev.To)
+}
+
+func TestMasterClosedEventFromCadence(t *testing.T) {
+	fields := []cadence.Field{
+		{Identifier: "asset", Type: cadence.StringType},
+		{Identifier: "unmintedEditions", Type: cadence.UInt64Type},
+	}
+
+	ev, err := MasterClosedEventFromCadence(newTestEvent("DigitalArt.MasterClosed", fields, []cadence.Value{
+		cadence.String("did:sequel:asset-id"),
+		cadence.UInt64(3),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, &MasterClosedEvent{
+		Asset:            "did:sequel:asset-id",
+		UnmintedEditions: 3,
+	}, ev)
+
+	_, err = MasterClosedEventFromCadence(newTestEvent("DigitalArt.MasterPaused", fields[:1], []cadence.Value{
+		cadence.String("did:sequel:asset-id"),
+	}))
+	require.Error(t, err)
+}
+
+func TestBidPlacedEventFromCadence(t *testing.T) {
+	fields := []cadence.Field{
+		{Identifier: "auctionID", Type: cadence.UInt64Type},
+		{Identifier: "sellerAddress", Type: cadence.AddressType},
+		{Identifier: "bidderAddress", Type: cadence.AddressType},
+		{Identifier: "amount", Type: cadence.UFix64Type},
+		{Identifier: "endTime", Type: cadence.UFix64Type},
+	}
+
+	ev, err := BidPlacedEventFromCadence(newTestEvent("SequelAuctions.BidPlaced", fields, []cadence.Value{
+		cadence.UInt64(42),
+		cadence.NewAddress(sequelAddress),
+		cadence.NewAddress(userAddress),
+		mustUFix64(t, "25.5"),
+		mustUFix64(t, "1700000000.25"),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, &BidPlacedEvent{
+		AuctionID:     42,
+		SellerAddress: sequelAddress,
+		BidderAddress: userAddress,
+		Amount:        evergreen.MustParseUFix64("25.5"),
+		EndTime:       time.Unix(1700000000, 250_000_000),
+	}, ev)
+
+	_, err = BidPlacedEventFromCadence(newTestEvent("SequelAuctions.BidPlaced", fields, []cadence.Value{
+		cadence.UInt64(42),
+		cadence.NewAddress(sequelAddress),
+		cadence.NewAddress(userAddress),
+		mustUFix64(t, "25.5"),
+		cadence.UInt64(1700000000),
+	}))
+	require.Error(t, err)
+}
+
+func TestTokenListedEventFromCadence(t *testing.T) {
+	paymentFields := []cadence.Field{
+		{Identifier: "role", Type: cadence.StringType},
+		{Identifier: "receiver", Type: cadence.AddressType},
+		{Identifier: "amount", Type: cadence.UFix64Type},
+		{Identifier: "rate", Type: cadence.UFix64Type},
+	}
+	paymentType := cadence.NewStructType(
+		common.AddressLocation{
+			Address: common.Address(sequelAddress),
+			Name:    common.AddressLocationPrefix,
+		},
+		"SequelMarketplace.Payment",
+		paymentFields,
+		nil,
+	)
+
+	fields := []cadence.Field{
+		{Identifier: "storefrontAddress", Type: cadence.AddressType},
+		{Identifier: "listingID", Type: cadence.UInt64Type},
+		{Identifier: "nftType", Type: cadence.StringType},
+		{Identifier: "nftID", Type: cadence.UInt64Type},
+		{Identifier: "paymentVaultType", Type: cadence.StringType},
+		{Identifier: "price", Type: cadence.UFix64Type},
+		{Identifier: "payments", Type: cadence.NewVariableSizedArrayType(paymentType)},
+		{Identifier: "asset", Type: cadence.StringType},
+		{Identifier: "metadataLink", Type: cadence.NewOptionalType(cadence.StringType)},
+	}
+
+	ev, err := TokenListedEventFromCadence(newTestEvent("SequelMarketplace.TokenListed", fields, []cadence.Value{
+		cadence.NewAddress(userAddress),
+		cadence.UInt64(42),
+		cadence.String("A.179b6b1cb6755e31.DigitalArt.NFT"),
+		cadence.UInt64(1),
+		cadence.String("A.0ae53cb6e3f42a79.FlowToken.Vault"),
+		mustUFix64(t, "200.0"),
+		cadence.NewArray([]cadence.Value{
+			cadence.NewStruct([]cadence.Value{
+				cadence.String("Artist"),
+				cadence.NewAddress(sequelAddress),
+				mustUFix64(t, "10.0"),
+				mustUFix64(t, "0.05"),
+			}).WithType(paymentType),
+			cadence.NewStruct([]cadence.Value{
+				cadence.String("Owner"),
+				cadence.NewAddress(userAddress),
+				mustUFix64(t, "190.0"),
+				mustUFix64(t, "0.95"),
+			}).WithType(paymentType),
+		}),
+		cadence.String("did:sequel:asset-id"),
+		cadence.NewOptional(cadence.String("link")),
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, userAddress, ev.StorefrontAddress)
+	assert.Equal(t, uint64(42), ev.ListingID)
+	assert.Equal(t, uint64(1), ev.NFTID)
+	assert.Equal(t, evergreen.MustParseUFix64("200.0"), ev.Price)
+	assert.Equal(t, "did:sequel:asset-id", ev.Asset)
+	require.NotNil(t, ev.MetadataLink)
+	assert.Equal(t, "link", *ev.MetadataLink)
+	assert.Equal(t, []*evergreen.Payment{
+		{Role: "Artist", Receiver: sequelAddress, Amount: evergreen.MustParseUFix64("10.0"), Rate: evergreen.MustParseUFix64("0.05")},
+		{Role: "Owner", Receiver: userAddress, Amount: evergreen.MustParseUFix64("190.0"), Rate: evergreen.MustParseUFix64("0.95")},
+	}, ev.Payments)
+}
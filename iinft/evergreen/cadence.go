@@ -18,23 +18,33 @@ func RoleFromCadence(val cadence.Value) (*Role, error) {
 	}
 
 	valStruct, ok := val.(cadence.Struct)
-	if !ok || valStruct.StructType.QualifiedIdentifier != "Evergreen.Role" || len(valStruct.FieldsMappedByName()) != 6 {
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "Evergreen.Role" || len(valStruct.FieldsMappedByName()) != 6 {
 		return nil, errors.New("bad Evergreen Role value")
 	}
 
 	fieldMap := valStruct.FieldsMappedByName()
 
+	id, idOK := fieldMap["id"].(cadence.String)
+	description, descriptionOK := fieldMap["description"].(cadence.String)
+	initialSaleCommission, initialSaleCommissionOK := fieldMap["initialSaleCommission"].(cadence.UFix64)
+	secondaryMarketCommission, secondaryMarketCommissionOK := fieldMap["secondaryMarketCommission"].(cadence.UFix64)
+	address, addressOK := fieldMap["address"].(cadence.Address)
+	receiverPathOpt, receiverPathOK := fieldMap["receiverPath"].(cadence.Optional)
+	if !idOK || !descriptionOK || !initialSaleCommissionOK || !secondaryMarketCommissionOK || !addressOK || !receiverPathOK {
+		return nil, errors.New("bad Evergreen Role value")
+	}
+
 	var receiverPath string
-	if opt := fieldMap["receiverPath"].(cadence.Optional).Value; opt != nil {
-		receiverPath = opt.String()
+	if receiverPathOpt.Value != nil {
+		receiverPath = receiverPathOpt.Value.String()
 	}
 
 	res := Role{
-		ID:                        string(fieldMap["id"].(cadence.String)),
-		Description:               string(fieldMap["description"].(cadence.String)),
-		InitialSaleCommission:     splash.ToFloat64(fieldMap["initialSaleCommission"]),
-		SecondaryMarketCommission: splash.ToFloat64(fieldMap["secondaryMarketCommission"]),
-		Address:                   flow.BytesToAddress(fieldMap["address"].(cadence.Address).Bytes()),
+		ID:                        string(id),
+		Description:               string(description),
+		InitialSaleCommission:     UFix64(initialSaleCommission),
+		SecondaryMarketCommission: UFix64(secondaryMarketCommission),
+		Address:                   flow.BytesToAddress(address.Bytes()),
 		ReceiverPath:              receiverPath,
 	}
 
@@ -53,8 +63,8 @@ func RoleToCadence(role *Role, evergreenAddr flow.Address) (cadence.Value, error
 	return cadence.NewStruct([]cadence.Value{
 		cadence.String(role.ID),
 		cadence.String(role.Description),
-		splash.UFix64FromFloat64(role.InitialSaleCommission),
-		splash.UFix64FromFloat64(role.SecondaryMarketCommission),
+		role.InitialSaleCommission.Cadence(),
+		role.SecondaryMarketCommission.Cadence(),
 		cadence.BytesToAddress(role.Address.Bytes()),
 		cadence.NewOptional(receiverPath),
 	}).WithType(cadence.NewStructType(
@@ -77,22 +87,25 @@ func ProfileFromCadence(val cadence.Value) (*Profile, error) {
 	}
 
 	valStruct, ok := val.(cadence.Struct)
-	if !ok || valStruct.StructType.QualifiedIdentifier != "Evergreen.Profile" || len(valStruct.FieldsMappedByName()) != 3 {
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "Evergreen.Profile" || len(valStruct.FieldsMappedByName()) != 3 {
 		return nil, errors.New("bad Evergreen Profile value")
 	}
 
 	fieldMap := valStruct.FieldsMappedByName()
 
+	id, idOK := fieldMap["id"].(cadence.String)
+	description, descriptionOK := fieldMap["description"].(cadence.String)
+	rolesArray, rolesOK := fieldMap["roles"].(cadence.Array)
+	if !idOK || !descriptionOK || !rolesOK {
+		return nil, errors.New("bad Evergreen Profile value")
+	}
+
 	res := Profile{
-		ID:          string(fieldMap["id"].(cadence.String)),
-		Description: string(fieldMap["description"].(cadence.String)),
+		ID:          string(id),
+		Description: string(description),
 		Roles:       []*Role{},
 	}
 
-	rolesArray, ok := fieldMap["roles"].(cadence.Array)
-	if !ok {
-		return nil, errors.New("bad Evergreen Profile value")
-	}
 	for _, roleVal := range rolesArray.Values {
 		role, err := RoleFromCadence(roleVal)
 		if err != nil {
@@ -104,8 +117,19 @@ func ProfileFromCadence(val cadence.Value) (*Profile, error) {
 	return &res, nil
 }
 
+// ProfileToCadence converts the given profile into a Cadence value.
+// The profile is validated first, see Profile.Validate.
 func ProfileToCadence(profile *Profile, evergreenAddr flow.Address) (cadence.Value, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	return ProfileToCadenceUnvalidated(profile, evergreenAddr)
+}
 
+// ProfileToCadenceUnvalidated works like ProfileToCadence, but doesn't validate the profile.
+// Use it to check how contracts handle invalid profiles.
+func ProfileToCadenceUnvalidated(profile *Profile, evergreenAddr flow.Address) (cadence.Value, error) {
 	roles := make([]cadence.Value, len(profile.Roles))
 	i := 0
 	var err error
@@ -18,8 +18,8 @@ func TestRoleToCadence_RoleFromCadence(t *testing.T) {
 	sourceRole := &Role{
 		ID:                        RoleArtist,
 		Description:               "Test Role",
-		InitialSaleCommission:     0.8,
-		SecondaryMarketCommission: 0.05,
+		InitialSaleCommission:     MustParseUFix64("0.8"),
+		SecondaryMarketCommission: MustParseUFix64("0.05"),
 		Address:                   artist,
 	}
 
@@ -41,8 +41,8 @@ func TestRoleToCadence_RoleFromCadence(t *testing.T) {
 	sourceRole = &Role{
 		ID:                        RoleArtist,
 		Description:               "Test Role",
-		InitialSaleCommission:     0.8,
-		SecondaryMarketCommission: 0.05,
+		InitialSaleCommission:     MustParseUFix64("0.8"),
+		SecondaryMarketCommission: MustParseUFix64("0.05"),
 		Address:                   artist,
 		ReceiverPath:              "/public/Test",
 	}
@@ -70,8 +70,8 @@ func TestProfileToCadence_ProfileFromCadence(t *testing.T) {
 			{
 				ID:                        RoleArtist,
 				Description:               "Test Role",
-				InitialSaleCommission:     0.8,
-				SecondaryMarketCommission: 0.05,
+				InitialSaleCommission:     MustParseUFix64("0.8"),
+				SecondaryMarketCommission: MustParseUFix64("0.05"),
 				Address:                   artist,
 			},
 		},
@@ -89,3 +89,27 @@ func TestProfileToCadence_ProfileFromCadence(t *testing.T) {
 	assert.Equal(t, 1, len(profile.Roles))
 	assert.Equal(t, sourceProfile.Roles[0].ID, profile.Roles[0].ID)
 }
+
+func TestProfileToCadenceUnvalidated(t *testing.T) {
+	invalidProfile := &Profile{
+		ID: "did:sequel:evergreen-invalid",
+		Roles: []*Role{
+			{
+				ID:                    RoleArtist,
+				InitialSaleCommission: MustParseUFix64("1.5"),
+				Address:               artist,
+			},
+		},
+	}
+
+	_, err := ProfileToCadence(invalidProfile, evergreenAddress)
+	require.Error(t, err)
+
+	val, err := ProfileToCadenceUnvalidated(invalidProfile, evergreenAddress)
+	require.NoError(t, err)
+
+	profile, err := ProfileFromCadence(val)
+	require.NoError(t, err)
+	assert.Equal(t, invalidProfile.ID, profile.ID)
+	assert.Equal(t, invalidProfile.Roles[0].InitialSaleCommission, profile.Roles[0].InitialSaleCommission)
+}
@@ -0,0 +1,62 @@
+package evergreen
+
+import (
+	"errors"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// MarshalJSONCDC encodes the role as a JSON-Cadence value of Evergreen.Role type,
+// i.e. to be used with `flow transactions send --args-json`.
+func (r *Role) MarshalJSONCDC(evergreenAddr flow.Address) ([]byte, error) {
+	val, err := RoleToCadence(r, evergreenAddr)
+	if err != nil {
+		return nil, err
+	}
+	return jsoncdc.Encode(val)
+}
+
+// UnmarshalJSONCDC decodes a JSON-Cadence value of Evergreen.Role type.
+func (r *Role) UnmarshalJSONCDC(data []byte) error {
+	val, err := jsoncdc.Decode(nil, data)
+	if err != nil {
+		return err
+	}
+	role, err := RoleFromCadence(val)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return errors.New("bad Evergreen Role value")
+	}
+	*r = *role
+	return nil
+}
+
+// MarshalJSONCDC encodes the profile as a JSON-Cadence value of Evergreen.Profile type,
+// i.e. to be used with `flow transactions send --args-json`. The profile is validated first.
+func (p *Profile) MarshalJSONCDC(evergreenAddr flow.Address) ([]byte, error) {
+	val, err := ProfileToCadence(p, evergreenAddr)
+	if err != nil {
+		return nil, err
+	}
+	return jsoncdc.Encode(val)
+}
+
+// UnmarshalJSONCDC decodes a JSON-Cadence value of Evergreen.Profile type.
+func (p *Profile) UnmarshalJSONCDC(data []byte) error {
+	val, err := jsoncdc.Decode(nil, data)
+	if err != nil {
+		return err
+	}
+	profile, err := ProfileFromCadence(val)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return errors.New("bad Evergreen Profile value")
+	}
+	*p = *profile
+	return nil
+}
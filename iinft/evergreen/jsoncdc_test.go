@@ -0,0 +1,75 @@
+package evergreen_test
+
+import (
+	"testing"
+
+	. "github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_JSONCDC(t *testing.T) {
+	profile := &Profile{
+		ID:          "did:sequel:evergreen1",
+		Description: "Test Profile",
+		Roles: []*Role{
+			{
+				ID:                        RoleArtist,
+				InitialSaleCommission:     MustParseUFix64("0.8"),
+				SecondaryMarketCommission: MustParseUFix64("0.05"),
+				Address:                   artist,
+				ReceiverPath:              "/public/flowTokenReceiver",
+			},
+			{
+				ID:                        RolePlatform,
+				InitialSaleCommission:     MustParseUFix64("0.2"),
+				SecondaryMarketCommission: MustParseUFix64("0.025"),
+				Address:                   platform,
+			},
+		},
+	}
+
+	data, err := profile.MarshalJSONCDC(evergreenAddress)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"id":"A.01cf0e2f2f715450.Evergreen.Profile"`)
+
+	var res Profile
+	require.NoError(t, res.UnmarshalJSONCDC(data))
+	assert.Equal(t, profile, &res)
+
+	// invalid profiles are rejected
+
+	profile.Roles[1].ID = RoleArtist
+	_, err = profile.MarshalJSONCDC(evergreenAddress)
+	assert.Error(t, err)
+
+	assert.Error(t, res.UnmarshalJSONCDC([]byte(`{"type":"String","value":"profile"}`)))
+}
+
+func TestRole_JSONCDC(t *testing.T) {
+	role := &Role{
+		ID:                        RoleArtist,
+		Description:               "Test Role",
+		InitialSaleCommission:     MustParseUFix64("0.8"),
+		SecondaryMarketCommission: MustParseUFix64("0.05"),
+		Address:                   artist,
+	}
+
+	data, err := role.MarshalJSONCDC(evergreenAddress)
+	require.NoError(t, err)
+
+	var res Role
+	require.NoError(t, res.UnmarshalJSONCDC(data))
+	assert.Equal(t, role, &res)
+
+	// mistyped fields are reported as errors
+
+	assert.Error(t, res.UnmarshalJSONCDC([]byte(`{"type":"Struct","value":{"id":"A.01cf0e2f2f715450.Evergreen.Role","fields":[
+		{"name":"id","value":{"type":"String","value":"Artist"}},
+		{"name":"description","value":{"type":"String","value":""}},
+		{"name":"initialSaleCommission","value":{"type":"String","value":"0.8"}},
+		{"name":"secondaryMarketCommission","value":{"type":"UFix64","value":"0.05000000"}},
+		{"name":"address","value":{"type":"Address","value":"0xf3fcd2c1a78f5eee"}},
+		{"name":"receiverPath","value":{"type":"Optional","value":null}}
+	]}}`)))
+}
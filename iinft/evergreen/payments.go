@@ -0,0 +1,206 @@
+package evergreen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+// MaxCollectors is the maximum number of previous owners that receive
+// a share of the Collector role's commission.
+const MaxCollectors = 10
+
+var (
+	ErrRateOutOfRange           = errors.New("rate must be in range [0..1]")
+	ErrResidualRateOutOfRange   = errors.New("residual rate must be in range [0..1]")
+	ErrMissingMandatoryReceiver = errors.New("missing fungible token receiver capability for mandatory payment recipient")
+	ErrFeeConflict              = errors.New("extra role conflicts with the platform fee")
+	ErrRatesExceedPrice         = errors.New("commissions and platform fee exceed the price")
+)
+
+type (
+	// Payment mirrors SequelMarketplace.Payment structure.
+	Payment struct {
+		// Role is the Evergreen role of the party that receives this payment
+		Role string
+		// Receiver is the receiving party's address
+		Receiver flow.Address
+		// Amount is the quantity of the fungible token that will be paid to the receiver.
+		Amount UFix64
+		// Rate is the percentage of the overall sale this payment represents.
+		Rate UFix64
+	}
+
+	// PaymentInstructions is the outcome of BuildPayments.
+	PaymentInstructions struct {
+		Payments []*Payment
+		// Residual is the part of the price not covered by the payments due to
+		// rounding. SequelMarketplace.payForMintedTokens sends it to the last receiver.
+		Residual UFix64
+	}
+
+	// ReceiverCheck reports if the given account has a valid fungible token receiver
+	// capability at the given path. It models receiverCap.check() in Cadence.
+	// receiverPath is empty if the payment goes to the default path: the royalty receiver
+	// path for Evergreen roles or the seller's vault path for the seller role.
+	ReceiverCheck func(roleID string, address flow.Address, receiverPath string) bool
+)
+
+// CommissionRate mirrors Evergreen.Role.commissionRate function.
+func (r *Role) CommissionRate(initialSale bool) UFix64 {
+	if initialSale {
+		return r.InitialSaleCommission
+	}
+	return r.SecondaryMarketCommission
+}
+
+// BuildPayments constructs a list of payments based on the given Evergreen profile.
+// Any residual amount goes to the given seller's address. It mirrors
+// SequelMarketplace.buildPayments: if hasReceiver returns false for a role,
+// the role is skipped and its share goes to the seller. If hasReceiver is nil,
+// all receivers are assumed to be available.
+// fee is the platform fee that applies to the sale (see iinft.FeePolicy), or nil if there is none.
+// Like SequelMarketplace, it's charged in addition to the profile's commissions.
+func BuildPayments(profile *Profile, seller flow.Address, sellerRole string, price UFix64, initialSale bool,
+	extraRoles []*Role, fee *Role, hasReceiver ReceiverCheck) (*PaymentInstructions, error) {
+	return BuildPaymentsWithCollectors(profile, seller, sellerRole, price, initialSale, extraRoles, fee, nil, hasReceiver)
+}
+
+// Collectors mirrors SequelMarketplace.collectors function. It returns up to MaxCollectors
+// most recent distinct previous owners, excluding the seller, from the earliest to the most recent one.
+func Collectors(previousOwners []flow.Address, seller flow.Address) []flow.Address {
+	var res []flow.Address
+	for i := len(previousOwners) - 1; i >= 0 && len(res) < MaxCollectors; i-- {
+		owner := previousOwners[i]
+		if owner != seller && !slices.Contains(res, owner) {
+			res = append(res, owner)
+		}
+	}
+	slices.Reverse(res)
+	return res
+}
+
+// BuildPaymentsWithCollectors mirrors SequelMarketplace.buildPaymentsWithCollectors.
+// It works like BuildPayments, but the commission of the Collector role is split equally
+// between the token's collectors (see Collectors). If there are no collectors,
+// the commission goes to the seller.
+func BuildPaymentsWithCollectors(profile *Profile, seller flow.Address, sellerRole string, price UFix64, initialSale bool,
+	extraRoles []*Role, fee *Role, previousOwners []flow.Address, hasReceiver ReceiverCheck) (*PaymentInstructions, error) {
+	if hasReceiver == nil {
+		hasReceiver = func(string, flow.Address, string) bool { return true }
+	}
+
+	if fee != nil {
+		for _, role := range extraRoles {
+			if role.ID == fee.ID {
+				return nil, fmt.Errorf("%w: %s", ErrFeeConflict, role.ID)
+			}
+		}
+		extraRoles = append(slices.Clone(extraRoles), fee)
+	}
+
+	var roles []*Role
+	if profile != nil {
+		roles = profile.Roles
+	}
+	totalRate := UFix64(0)
+	for _, role := range slices.Concat(roles, extraRoles) {
+		rate := role.CommissionRate(initialSale)
+		if rate > UFix64One {
+			return nil, fmt.Errorf("%w: %s", ErrRateOutOfRange, role.ID)
+		}
+		var err error
+		if totalRate, err = totalRate.Add(rate); err != nil {
+			return nil, err
+		}
+	}
+	if totalRate > UFix64One {
+		return nil, ErrRatesExceedPrice
+	}
+
+	res := &PaymentInstructions{}
+	residualRate := UFix64One
+
+	addPayment := func(roleID string, address flow.Address, receiverPath string, rate UFix64, mustSucceed bool) error {
+		if rate > UFix64One {
+			return fmt.Errorf("%w: %s", ErrRateOutOfRange, roleID)
+		}
+
+		if rate == 0 {
+			return nil
+		}
+
+		amount, err := price.Mul(rate)
+		if err != nil {
+			return err
+		}
+
+		if hasReceiver(roleID, address, receiverPath) {
+			res.Payments = append(res.Payments, &Payment{
+				Role:     roleID,
+				Receiver: address,
+				Amount:   amount,
+				Rate:     rate,
+			})
+			if residualRate, err = residualRate.Sub(rate); err != nil {
+				return fmt.Errorf("%w: %s", ErrResidualRateOutOfRange, roleID)
+			}
+		} else if mustSucceed {
+			return ErrMissingMandatoryReceiver
+		}
+
+		return nil
+	}
+
+	collectors := Collectors(previousOwners, seller)
+
+	if profile != nil {
+		for _, role := range profile.Roles {
+			if role.ID == RoleCollector {
+				rate := role.CommissionRate(initialSale)
+				if rate > UFix64One {
+					return nil, fmt.Errorf("%w: %s", ErrRateOutOfRange, role.ID)
+				}
+				if len(collectors) > 0 {
+					share, err := rate.Div(UFix64(len(collectors)) * UFix64One)
+					if err != nil {
+						return nil, err
+					}
+					for _, collector := range collectors {
+						if err := addPayment(role.ID, collector, role.ReceiverPath, share, false); err != nil {
+							return nil, err
+						}
+					}
+				}
+				continue
+			}
+			if err := addPayment(role.ID, role.Address, role.ReceiverPath, role.CommissionRate(initialSale), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, role := range extraRoles {
+		if err := addPayment(role.ID, role.Address, role.ReceiverPath, role.CommissionRate(initialSale), false); err != nil {
+			return nil, err
+		}
+	}
+
+	if residualRate > 0 {
+		if err := addPayment(sellerRole, seller, "", residualRate, true); err != nil {
+			return nil, err
+		}
+	}
+
+	res.Residual = price
+	for _, p := range res.Payments {
+		var err error
+		if res.Residual, err = res.Residual.Sub(p.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
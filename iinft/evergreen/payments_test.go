@@ -0,0 +1,251 @@
+package evergreen_test
+
+import (
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	. "github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	platform = flow.HexToAddress("0x045a1763c93006ca")
+	seller   = flow.HexToAddress("0x120e725050340cab")
+)
+
+func TestBuildPayments(t *testing.T) {
+	profile := &Profile{
+		ID: "did:sequel:evergreen1",
+		Roles: []*Role{
+			{
+				ID:                        RoleArtist,
+				InitialSaleCommission:     MustParseUFix64("0.8"),
+				SecondaryMarketCommission: MustParseUFix64("0.05"),
+				Address:                   artist,
+			},
+			{
+				ID:                        RolePlatform,
+				InitialSaleCommission:     MustParseUFix64("0.2"),
+				SecondaryMarketCommission: MustParseUFix64("0.025"),
+				Address:                   platform,
+			},
+		},
+	}
+
+	t.Run("Initial sale", func(t *testing.T) {
+		res, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), true, nil, nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("80.0"), Rate: MustParseUFix64("0.8")},
+			{Role: RolePlatform, Receiver: platform, Amount: MustParseUFix64("20.0"), Rate: MustParseUFix64("0.2")},
+		}, res.Payments)
+		assert.Equal(t, UFix64Zero, res.Residual)
+	})
+
+	t.Run("Secondary sale", func(t *testing.T) {
+		res, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), false, nil, nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("5.0"), Rate: MustParseUFix64("0.05")},
+			{Role: RolePlatform, Receiver: platform, Amount: MustParseUFix64("2.5"), Rate: MustParseUFix64("0.025")},
+			{Role: RoleOwner, Receiver: seller, Amount: MustParseUFix64("92.5"), Rate: MustParseUFix64("0.925")},
+		}, res.Payments)
+	})
+
+	t.Run("Residual", func(t *testing.T) {
+		res, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("0.00000099"), false, nil, nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("0.00000004"), Rate: MustParseUFix64("0.05")},
+			{Role: RolePlatform, Receiver: platform, Amount: MustParseUFix64("0.00000002"), Rate: MustParseUFix64("0.025")},
+			{Role: RoleOwner, Receiver: seller, Amount: MustParseUFix64("0.00000091"), Rate: MustParseUFix64("0.925")},
+		}, res.Payments)
+		assert.Equal(t, MustParseUFix64("0.00000002"), res.Residual)
+	})
+
+	t.Run("Missing receiver", func(t *testing.T) {
+		hasReceiver := func(_ string, address flow.Address, _ string) bool {
+			return address != platform
+		}
+
+		res, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), true, nil, nil, hasReceiver)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("80.0"), Rate: MustParseUFix64("0.8")},
+			{Role: RoleOwner, Receiver: seller, Amount: MustParseUFix64("20.0"), Rate: MustParseUFix64("0.2")},
+		}, res.Payments)
+	})
+
+	t.Run("Missing seller receiver", func(t *testing.T) {
+		hasReceiver := func(roleID string, _ flow.Address, _ string) bool {
+			return roleID != RoleOwner
+		}
+
+		_, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), false, nil, nil, hasReceiver)
+		assert.ErrorIs(t, err, ErrMissingMandatoryReceiver)
+
+		// the seller isn't paid if the roles take everything
+
+		_, err = BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), true, nil, nil, hasReceiver)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Extra roles", func(t *testing.T) {
+		extraRoles := []*Role{
+			{ID: "Extra", SecondaryMarketCommission: MustParseUFix64("0.02"), Address: platform, ReceiverPath: "/public/flowTokenReceiver"},
+		}
+
+		var paths []string
+		hasReceiver := func(_ string, _ flow.Address, receiverPath string) bool {
+			paths = append(paths, receiverPath)
+			return true
+		}
+
+		res, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), false, extraRoles, nil, hasReceiver)
+		require.NoError(t, err)
+
+		require.Len(t, res.Payments, 4)
+		assert.Equal(t, &Payment{Role: "Extra", Receiver: platform, Amount: MustParseUFix64("2.0"), Rate: MustParseUFix64("0.02")}, res.Payments[2])
+		assert.Equal(t, MustParseUFix64("90.5"), res.Payments[3].Amount)
+		assert.Equal(t, []string{"", "", "/public/flowTokenReceiver", ""}, paths)
+	})
+
+	fee := &Role{
+		ID:                        RolePlatform,
+		InitialSaleCommission:     MustParseUFix64("0.01"),
+		SecondaryMarketCommission: MustParseUFix64("0.02"),
+		Address:                   platform,
+	}
+
+	t.Run("Platform fee", func(t *testing.T) {
+		// the fee is charged even though the profile includes the Platform role
+		res, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), false, nil, fee, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("5.0"), Rate: MustParseUFix64("0.05")},
+			{Role: RolePlatform, Receiver: platform, Amount: MustParseUFix64("2.5"), Rate: MustParseUFix64("0.025")},
+			{Role: RolePlatform, Receiver: platform, Amount: MustParseUFix64("2.0"), Rate: MustParseUFix64("0.02")},
+			{Role: RoleOwner, Receiver: seller, Amount: MustParseUFix64("90.5"), Rate: MustParseUFix64("0.905")},
+		}, res.Payments)
+	})
+
+	t.Run("Extra role conflicts with the fee", func(t *testing.T) {
+		extraRoles := []*Role{
+			{ID: RolePlatform, SecondaryMarketCommission: MustParseUFix64("0.0"), Address: seller},
+		}
+
+		_, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), false, extraRoles, fee, nil)
+		assert.ErrorIs(t, err, ErrFeeConflict)
+	})
+
+	t.Run("Commissions and fee greater than 1.0", func(t *testing.T) {
+		_, err := BuildPayments(profile, seller, RoleOwner, MustParseUFix64("100.0"), true, nil, fee, nil)
+		assert.ErrorIs(t, err, ErrRatesExceedPrice)
+	})
+
+	t.Run("Rate out of range", func(t *testing.T) {
+		badProfile := &Profile{
+			Roles: []*Role{
+				{ID: RoleArtist, InitialSaleCommission: MustParseUFix64("1.25"), Address: artist},
+			},
+		}
+
+		_, err := BuildPayments(badProfile, seller, RoleOwner, MustParseUFix64("100.0"), true, nil, nil, nil)
+		assert.ErrorIs(t, err, ErrRateOutOfRange)
+	})
+
+	t.Run("Sum of rates greater than 1.0", func(t *testing.T) {
+		badProfile := &Profile{
+			Roles: []*Role{
+				{ID: RoleArtist, InitialSaleCommission: MustParseUFix64("0.8"), Address: artist},
+				{ID: RolePlatform, InitialSaleCommission: MustParseUFix64("0.8"), Address: platform},
+			},
+		}
+
+		_, err := BuildPayments(badProfile, seller, RoleOwner, MustParseUFix64("100.0"), true, nil, nil, nil)
+		assert.ErrorIs(t, err, ErrRatesExceedPrice)
+	})
+}
+
+func TestCollectors(t *testing.T) {
+	c1 := flow.HexToAddress("0x01")
+	c2 := flow.HexToAddress("0x02")
+
+	assert.Empty(t, Collectors(nil, seller))
+	assert.Empty(t, Collectors([]flow.Address{seller}, seller))
+	assert.Equal(t, []flow.Address{c2, c1}, Collectors([]flow.Address{c1, c2, seller, c1}, seller))
+
+	var many []flow.Address
+	for i := 1; i <= MaxCollectors+2; i++ {
+		many = append(many, flow.BytesToAddress([]byte{byte(i)}))
+	}
+	res := Collectors(many, seller)
+	assert.Len(t, res, MaxCollectors)
+	assert.Equal(t, many[2:], res)
+}
+
+func TestBuildPaymentsWithCollectors(t *testing.T) {
+	c1 := flow.HexToAddress("0x01")
+	c2 := flow.HexToAddress("0x02")
+	c3 := flow.HexToAddress("0x03")
+
+	profile := &Profile{
+		ID: "did:sequel:evergreen1",
+		Roles: []*Role{
+			{
+				ID:                        RoleArtist,
+				InitialSaleCommission:     MustParseUFix64("1.0"),
+				SecondaryMarketCommission: MustParseUFix64("0.05"),
+				Address:                   artist,
+			},
+			{
+				ID:                        RoleCollector,
+				SecondaryMarketCommission: MustParseUFix64("0.1"),
+			},
+		},
+	}
+
+	t.Run("Secondary sale", func(t *testing.T) {
+		res, err := BuildPaymentsWithCollectors(profile, seller, RoleOwner, MustParseUFix64("1.0"), false, nil, nil,
+			[]flow.Address{c1, c2, c3, seller}, nil)
+		require.NoError(t, err)
+
+		// the price is 1.0, so the amounts equal the rates
+		share := MustParseUFix64("0.03333333")
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("0.05"), Rate: MustParseUFix64("0.05")},
+			{Role: RoleCollector, Receiver: c1, Amount: share, Rate: share},
+			{Role: RoleCollector, Receiver: c2, Amount: share, Rate: share},
+			{Role: RoleCollector, Receiver: c3, Amount: share, Rate: share},
+			{Role: RoleOwner, Receiver: seller, Amount: MustParseUFix64("0.85000001"), Rate: MustParseUFix64("0.85000001")},
+		}, res.Payments)
+		assert.Equal(t, UFix64Zero, res.Residual)
+	})
+
+	t.Run("No collectors", func(t *testing.T) {
+		res, err := BuildPaymentsWithCollectors(profile, seller, RoleOwner, MustParseUFix64("100.0"), false, nil, nil,
+			[]flow.Address{seller}, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("5.0"), Rate: MustParseUFix64("0.05")},
+			{Role: RoleOwner, Receiver: seller, Amount: MustParseUFix64("95.0"), Rate: MustParseUFix64("0.95")},
+		}, res.Payments)
+	})
+
+	t.Run("Initial sale", func(t *testing.T) {
+		res, err := BuildPaymentsWithCollectors(profile, seller, RoleArtist, MustParseUFix64("100.0"), true, nil, nil,
+			[]flow.Address{c1}, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*Payment{
+			{Role: RoleArtist, Receiver: artist, Amount: MustParseUFix64("100.0"), Rate: MustParseUFix64("1.0")},
+		}, res.Payments)
+	})
+}
@@ -0,0 +1,189 @@
+package evergreen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// UFix64 is a fixed-point number with 8 decimal places that follows the semantics
+// of Cadence UFix64 type. Use it whenever the result needs to match on-chain calculations
+// to the last digit.
+//
+// UFix64 values are marshalled to JSON as decimal strings, i.e. "0.05".
+// JSON numbers are accepted, too, for compatibility with documents
+// created when commissions were represented as float64.
+type UFix64 uint64
+
+const (
+	// UFix64Factor is the scale of UFix64 values.
+	UFix64Factor = 100_000_000
+
+	UFix64Zero UFix64 = 0
+	UFix64One  UFix64 = UFix64Factor
+)
+
+var (
+	ErrUFix64Overflow       = errors.New("UFix64 overflow")
+	ErrUFix64Underflow      = errors.New("UFix64 underflow")
+	ErrUFix64DivisionByZero = errors.New("UFix64 division by zero")
+
+	ufix64FactorBig = big.NewInt(UFix64Factor)
+)
+
+// ParseUFix64 parses a decimal string, i.e. "10.5" or "10", into a UFix64 value.
+func ParseUFix64(s string) (UFix64, error) {
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	v, err := cadence.NewUFix64(s)
+	if err != nil {
+		return 0, err
+	}
+	return UFix64(v), nil
+}
+
+// MustParseUFix64 is like ParseUFix64 but panics if the string cannot be parsed.
+func MustParseUFix64(s string) UFix64 {
+	v, err := ParseUFix64(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// UFix64FromFloat64 converts a float64 value into UFix64. The value is rounded
+// to 4 decimal places, the same way float64 commissions used to be sent to Cadence.
+func UFix64FromFloat64(f float64) (UFix64, error) {
+	if math.IsNaN(f) || f < 0 {
+		return 0, fmt.Errorf("bad UFix64 value: %v", f)
+	}
+	return ParseUFix64(fmt.Sprintf("%.4f", f))
+}
+
+// String returns the decimal representation of the value, as printed by Cadence.
+func (v UFix64) String() string {
+	return cadence.UFix64(v).String()
+}
+
+// ShortString returns the decimal representation of the value without trailing zeros,
+// i.e. "0.05" or "1.0".
+func (v UFix64) ShortString() string {
+	s := strings.TrimRight(v.String(), "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	return s
+}
+
+// Float64 returns the (possibly inexact) float64 representation of the value.
+func (v UFix64) Float64() float64 {
+	f, _ := strconv.ParseFloat(v.String(), 64)
+	return f
+}
+
+// Cadence returns the value as cadence.UFix64.
+func (v UFix64) Cadence() cadence.UFix64 {
+	return cadence.UFix64(v)
+}
+
+// Add returns v + other or ErrUFix64Overflow.
+func (v UFix64) Add(other UFix64) (UFix64, error) {
+	res := v + other
+	if res < v {
+		return 0, ErrUFix64Overflow
+	}
+	return res, nil
+}
+
+// Sub returns v - other or ErrUFix64Underflow.
+func (v UFix64) Sub(other UFix64) (UFix64, error) {
+	if other > v {
+		return 0, ErrUFix64Underflow
+	}
+	return v - other, nil
+}
+
+// Cmp compares v and other and returns -1 if v < other, 0 if v == other and +1 if v > other.
+func (v UFix64) Cmp(other UFix64) int {
+	switch {
+	case v < other:
+		return -1
+	case v > other:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Mul returns v * other or ErrUFix64Overflow. Like in Cadence, the result is truncated
+// to 8 decimal places.
+func (v UFix64) Mul(other UFix64) (UFix64, error) {
+	res := new(big.Int).Mul(new(big.Int).SetUint64(uint64(v)), new(big.Int).SetUint64(uint64(other)))
+	res.Quo(res, ufix64FactorBig)
+	if !res.IsUint64() {
+		return 0, ErrUFix64Overflow
+	}
+	return UFix64(res.Uint64()), nil
+}
+
+// Div returns v / other or an error. Like in Cadence, the result is truncated
+// to 8 decimal places.
+func (v UFix64) Div(other UFix64) (UFix64, error) {
+	if other == 0 {
+		return 0, ErrUFix64DivisionByZero
+	}
+	res := new(big.Int).Mul(new(big.Int).SetUint64(uint64(v)), ufix64FactorBig)
+	res.Quo(res, new(big.Int).SetUint64(uint64(other)))
+	if !res.IsUint64() {
+		return 0, ErrUFix64Overflow
+	}
+	return UFix64(res.Uint64()), nil
+}
+
+func (v UFix64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.ShortString())
+}
+
+func (v *UFix64) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		// legacy documents store values as JSON numbers
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("bad UFix64 value: %s", data)
+		}
+		s = n.String()
+		if _, err := ParseUFix64(s); err != nil {
+			// fall back to float64 for numbers like 1e-2 or 0.30000000000000004
+			f, err := n.Float64()
+			if err != nil {
+				return err
+			}
+			s = strconv.FormatFloat(f, 'f', 8, 64)
+		}
+	}
+
+	res, err := ParseUFix64(s)
+	if err != nil {
+		return fmt.Errorf("bad UFix64 value %q: %w", s, err)
+	}
+	*v = res
+	return nil
+}
@@ -0,0 +1,111 @@
+package evergreen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUFix64(t *testing.T) {
+	v, err := ParseUFix64("10.00000007")
+	require.NoError(t, err)
+	assert.Equal(t, UFix64(1_000_000_007), v)
+	assert.Equal(t, "10.00000007", v.String())
+	assert.Equal(t, 10.00000007, v.Float64())
+
+	_, err = ParseUFix64("-1.0")
+	assert.Error(t, err)
+
+	// multiplication truncates, like in Cadence
+
+	res, err := v.Mul(MustParseUFix64("0.3333"))
+	require.NoError(t, err)
+	assert.Equal(t, "3.33300002", res.String())
+
+	_, err = MustParseUFix64("184467440737.0").Mul(MustParseUFix64("2.0"))
+	assert.ErrorIs(t, err, ErrUFix64Overflow)
+
+	_, err = UFix64One.Sub(MustParseUFix64("1.00000001"))
+	assert.ErrorIs(t, err, ErrUFix64Underflow)
+
+	_, err = UFix64(1<<64 - 1).Add(1)
+	assert.ErrorIs(t, err, ErrUFix64Overflow)
+
+	v, err = UFix64FromFloat64(0.05)
+	require.NoError(t, err)
+	assert.Equal(t, MustParseUFix64("0.05"), v)
+
+	res, err = MustParseUFix64("1.0").Div(MustParseUFix64("3.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.33333333", res.String())
+
+	_, err = UFix64One.Div(UFix64Zero)
+	assert.ErrorIs(t, err, ErrUFix64DivisionByZero)
+
+	assert.Equal(t, 0, MustParseUFix64("0.3").Cmp(MustParseUFix64("0.1")+MustParseUFix64("0.2")))
+	assert.Equal(t, -1, MustParseUFix64("0.1").Cmp(MustParseUFix64("0.2")))
+	assert.Equal(t, 1, MustParseUFix64("1").Cmp(MustParseUFix64("0.99999999")))
+
+	assert.Equal(t, "0.05", MustParseUFix64("0.05").ShortString())
+	assert.Equal(t, "1.0", UFix64One.ShortString())
+	assert.Equal(t, "0.0", UFix64Zero.ShortString())
+}
+
+func TestUFix64_JSON(t *testing.T) {
+	data, err := json.Marshal(MustParseUFix64("0.05"))
+	require.NoError(t, err)
+	assert.Equal(t, `"0.05"`, string(data))
+
+	for input, expected := range map[string]string{
+		`"0.05"`:              "0.05",
+		`"1"`:                 "1.0",
+		`0.05`:                "0.05",
+		`1`:                   "1.0",
+		`5e-2`:                "0.05",
+		`0.30000000000000004`: "0.3",
+		`"0.12345678"`:        "0.12345678",
+	} {
+		var v UFix64
+		require.NoError(t, json.Unmarshal([]byte(input), &v), input)
+		assert.Equal(t, MustParseUFix64(expected), v, input)
+	}
+
+	for _, input := range []string{`"-1.0"`, `-1.0`, `"abc"`, `"0.123456789"`, `true`} {
+		var v UFix64
+		assert.Error(t, json.Unmarshal([]byte(input), &v), input)
+	}
+}
+
+func TestRole_JSON(t *testing.T) {
+	// documents created when commissions were float64 values
+
+	var role Role
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "Artist",
+		"description": "",
+		"initialSaleCommission": 0.8,
+		"secondaryMarketCommission": 0.05,
+		"addr": "f3fcd2c1a78f5eee"
+	}`), &role))
+
+	assert.Equal(t, MustParseUFix64("0.8"), role.InitialSaleCommission)
+	assert.Equal(t, MustParseUFix64("0.05"), role.SecondaryMarketCommission)
+	assert.Equal(t, artist, role.Address)
+
+	data, err := json.Marshal(&role)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "Artist",
+		"description": "",
+		"initialSaleCommission": "0.8",
+		"secondaryMarketCommission": "0.05",
+		"addr": "f3fcd2c1a78f5eee"
+	}`, string(data))
+
+	var roundTrip Role
+	require.NoError(t, json.Unmarshal(data, &roundTrip))
+	assert.Equal(t, role, roundTrip)
+}
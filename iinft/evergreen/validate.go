@@ -0,0 +1,140 @@
+package evergreen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/splash"
+)
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type (
+	// ValidationError describes a single problem with an Evergreen profile.
+	ValidationError struct {
+		// RoleIndex is the index of the offending role in Profile.Roles
+		// or -1 if the error relates to the profile as a whole.
+		RoleIndex int
+		// RoleID is the ID of the offending role, if any.
+		RoleID string
+		// Field is the name of the offending field, as used in JSON representation.
+		Field string
+		// Message describes the problem.
+		Message string
+	}
+
+	// ValidationErrors is a list of problems found by Profile.Validate.
+	ValidationErrors []*ValidationError
+)
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	if e.RoleIndex < 0 {
+		sb.WriteString("profile")
+	} else {
+		fmt.Fprintf(&sb, "role %d", e.RoleIndex)
+		if e.RoleID != "" {
+			fmt.Fprintf(&sb, " (%s)", e.RoleID)
+		}
+	}
+	if e.Field != "" {
+		sb.WriteString(" " + e.Field)
+	}
+	sb.WriteString(": " + e.Message)
+	return sb.String()
+}
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "invalid Evergreen profile: " + strings.Join(msgs, "; ")
+}
+
+// ForRole returns errors that relate to the role with the given index.
+func (e ValidationErrors) ForRole(index int) ValidationErrors {
+	var res ValidationErrors
+	for _, err := range e {
+		if err.RoleIndex == index {
+			res = append(res, err)
+		}
+	}
+	return res
+}
+
+// Validate checks that the profile will be accepted by Evergreen and SequelMarketplace
+// contracts. It returns ValidationErrors if any problems were found, or nil otherwise.
+func (p *Profile) Validate() error {
+	var errs ValidationErrors
+
+	profileErr := func(field, msg string) {
+		errs = append(errs, &ValidationError{RoleIndex: -1, Field: field, Message: msg})
+	}
+
+	if p.ID == "" {
+		profileErr("id", "must not be empty")
+	}
+
+	var initialSaleTotal, secondaryMarketTotal UFix64
+	seenIDs := make(map[string]int, len(p.Roles))
+
+	for i, role := range p.Roles {
+		if role == nil {
+			errs = append(errs, &ValidationError{RoleIndex: i, Message: "must not be nil"})
+			continue
+		}
+
+		roleErr := func(field, msg string) {
+			errs = append(errs, &ValidationError{RoleIndex: i, RoleID: role.ID, Field: field, Message: msg})
+		}
+
+		if role.ID == "" {
+			roleErr("id", "must not be empty")
+		} else if prev, found := seenIDs[role.ID]; found {
+			roleErr("id", fmt.Sprintf("duplicates role %d", prev))
+		} else {
+			seenIDs[role.ID] = i
+		}
+
+		if role.InitialSaleCommission > UFix64One {
+			roleErr("initialSaleCommission", "must be in range [0..1]")
+		} else {
+			initialSaleTotal += role.InitialSaleCommission
+		}
+
+		if role.SecondaryMarketCommission > UFix64One {
+			roleErr("secondaryMarketCommission", "must be in range [0..1]")
+		} else {
+			secondaryMarketTotal += role.SecondaryMarketCommission
+		}
+
+		// the Collector role is paid to the token's previous owners, so its address isn't used
+		if role.Address == flow.EmptyAddress && role.ID != RoleCollector {
+			roleErr("addr", "must not be empty")
+		}
+
+		if role.ReceiverPath != "" {
+			path, err := splash.StringToPath(role.ReceiverPath)
+			if err != nil || path.Domain != common.PathDomainPublic || !identifierRegex.MatchString(path.Identifier) {
+				roleErr("receiverPath", "must be a public path, i.e. /public/flowTokenReceiver")
+			}
+		}
+	}
+
+	if initialSaleTotal > UFix64One {
+		profileErr("roles", fmt.Sprintf("initial sale commissions add up to %s, more than 1.0", initialSaleTotal))
+	}
+	if secondaryMarketTotal > UFix64One {
+		profileErr("roles", fmt.Sprintf("secondary market commissions add up to %s, more than 1.0", secondaryMarketTotal))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
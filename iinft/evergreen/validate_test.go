@@ -0,0 +1,112 @@
+package evergreen_test
+
+import (
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	. "github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_Validate(t *testing.T) {
+	validProfile := func() *Profile {
+		return &Profile{
+			ID: "did:sequel:evergreen1",
+			Roles: []*Role{
+				{
+					ID:                        RoleArtist,
+					InitialSaleCommission:     MustParseUFix64("0.8"),
+					SecondaryMarketCommission: MustParseUFix64("0.05"),
+					Address:                   artist,
+					ReceiverPath:              "/public/flowTokenReceiver",
+				},
+				{
+					ID:                        RolePlatform,
+					InitialSaleCommission:     MustParseUFix64("0.2"),
+					SecondaryMarketCommission: MustParseUFix64("0.025"),
+					Address:                   platform,
+				},
+			},
+		}
+	}
+
+	require.NoError(t, validProfile().Validate())
+	require.NoError(t, (&Profile{ID: "did:sequel:evergreen1"}).Validate())
+
+	// the Collector role doesn't need an address
+
+	withCollector := validProfile()
+	withCollector.Roles = append(withCollector.Roles, &Role{ID: RoleCollector, SecondaryMarketCommission: MustParseUFix64("0.1")})
+	require.NoError(t, withCollector.Validate())
+
+	t.Run("Profile errors", func(t *testing.T) {
+		profile := validProfile()
+		profile.ID = ""
+		profile.Roles[1].InitialSaleCommission = MustParseUFix64("0.25")
+
+		err := profile.Validate()
+		require.Error(t, err)
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Equal(t, ValidationErrors{
+			{RoleIndex: -1, Field: "id", Message: "must not be empty"},
+			{RoleIndex: -1, Field: "roles", Message: "initial sale commissions add up to 1.05000000, more than 1.0"},
+		}, errs)
+		assert.Equal(t, "invalid Evergreen profile: profile id: must not be empty; "+
+			"profile roles: initial sale commissions add up to 1.05000000, more than 1.0", err.Error())
+	})
+
+	t.Run("Role errors", func(t *testing.T) {
+		profile := validProfile()
+		profile.Roles = append(profile.Roles,
+			&Role{
+				ID:                        RoleArtist,
+				InitialSaleCommission:     MustParseUFix64("1.00000001"),
+				SecondaryMarketCommission: MustParseUFix64("1.5"),
+				Address:                   flow.EmptyAddress,
+				ReceiverPath:              "/storage/vault",
+			},
+			nil,
+			&Role{
+				Address:      artist,
+				ReceiverPath: "/public/bad path",
+			},
+		)
+
+		err := profile.Validate()
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+
+		assert.Empty(t, errs.ForRole(0))
+		assert.Empty(t, errs.ForRole(1))
+		assert.Equal(t, ValidationErrors{
+			{RoleIndex: 2, RoleID: RoleArtist, Field: "id", Message: "duplicates role 0"},
+			{RoleIndex: 2, RoleID: RoleArtist, Field: "initialSaleCommission", Message: "must be in range [0..1]"},
+			{RoleIndex: 2, RoleID: RoleArtist, Field: "secondaryMarketCommission", Message: "must be in range [0..1]"},
+			{RoleIndex: 2, RoleID: RoleArtist, Field: "addr", Message: "must not be empty"},
+			{RoleIndex: 2, RoleID: RoleArtist, Field: "receiverPath", Message: "must be a public path, i.e. /public/flowTokenReceiver"},
+		}, errs.ForRole(2))
+		assert.Equal(t, ValidationErrors{
+			{RoleIndex: 3, Message: "must not be nil"},
+		}, errs.ForRole(3))
+		assert.Equal(t, ValidationErrors{
+			{RoleIndex: 4, Field: "id", Message: "must not be empty"},
+			{RoleIndex: 4, Field: "receiverPath", Message: "must be a public path, i.e. /public/flowTokenReceiver"},
+		}, errs.ForRole(4))
+		assert.Equal(t, "role 2 (Artist) addr: must not be empty", errs.ForRole(2)[3].Error())
+		assert.Equal(t, "role 3: must not be nil", errs.ForRole(3)[0].Error())
+	})
+
+	t.Run("ProfileToCadence rejects invalid profiles", func(t *testing.T) {
+		profile := validProfile()
+		profile.Roles[0].Address = flow.EmptyAddress
+
+		_, err := ProfileToCadence(profile, evergreenAddress)
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Len(t, errs, 1)
+	})
+}
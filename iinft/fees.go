@@ -0,0 +1,116 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+
+	"github.com/onflow/cadence"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+)
+
+// FeePolicy mirrors SequelMarketplace.FeePolicy structure. It describes platform fees
+// charged by SequelMarketplace in addition to the commissions defined in tokens' Evergreen profiles.
+// Each fee is an Evergreen role: its commission rates apply to initial and secondary sales respectively.
+type FeePolicy struct {
+	// Default applies to payment vault types that don't have a specific fee.
+	Default *evergreen.Role
+	// Fees are keyed by payment vault type identifiers, i.e. "A.0ae53cb6e3f42a79.FlowToken.Vault".
+	Fees map[string]*evergreen.Role
+}
+
+// Fee returns the fee that applies to payments in the given vault type, or nil if there is no fee.
+func (p *FeePolicy) Fee(paymentVaultType string) *evergreen.Role {
+	if fee, found := p.Fees[paymentVaultType]; found {
+		return fee
+	}
+	return p.Default
+}
+
+// FeePolicyFromCadence decodes SequelMarketplace.FeePolicy value.
+func FeePolicyFromCadence(val cadence.Value) (*FeePolicy, error) {
+	valStruct, ok := val.(cadence.Struct)
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "SequelMarketplace.FeePolicy" {
+		return nil, errors.New("not a SequelMarketplace.FeePolicy value")
+	}
+
+	fields, err := structFields(valStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	res := FeePolicy{
+		Fees: make(map[string]*evergreen.Role),
+	}
+
+	defaultFee, ok := fields["defaultFee"].(cadence.Optional)
+	if !ok {
+		return nil, errors.New("bad defaultFee value")
+	}
+	if defaultFee.Value != nil {
+		if res.Default, err = evergreen.RoleFromCadence(defaultFee.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	fees, ok := fields["fees"].(cadence.Dictionary)
+	if !ok {
+		return nil, errors.New("bad fees value")
+	}
+	for _, pair := range fees.Pairs {
+		key, ok := pair.Key.(cadence.String)
+		if !ok {
+			return nil, errors.New("bad fees key")
+		}
+		if res.Fees[string(key)], err = evergreen.RoleFromCadence(pair.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+// GetFeePolicy returns the platform fee policy.
+func (c *Client) GetFeePolicy(ctx context.Context) (*FeePolicy, error) {
+	val, err := c.se.NewScript("marketplace_get_fee_policy").RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return FeePolicyFromCadence(val)
+}
+
+// SetFee sets the platform fee for payments in the given fungible token, or the default fee,
+// if token is nil. The fee is charged by listings, offers, auctions and primary sales
+// created after the update, in addition to the commissions in the token's Evergreen profile.
+func (c *Client) SetFee(ctx context.Context, token *FungibleTokenContract, fee *evergreen.Role) error {
+	feeVal, err := evergreen.RoleToCadence(fee, c.se.ContractAddress("Evergreen"))
+	if err != nil {
+		return err
+	}
+
+	ftAddress, ftName := optionalTokenContract(token)
+	_, err = c.runAsAdmin(ctx, c.se.NewTransaction("marketplace_set_fee").
+		Argument(ftAddress).
+		Argument(ftName).
+		Argument(feeVal))
+
+	return err
+}
+
+// RemoveFee removes the platform fee for payments in the given fungible token,
+// or the default fee, if token is nil.
+func (c *Client) RemoveFee(ctx context.Context, token *FungibleTokenContract) error {
+	ftAddress, ftName := optionalTokenContract(token)
+	_, err := c.runAsAdmin(ctx, c.se.NewTransaction("marketplace_remove_fee").
+		Argument(ftAddress).
+		Argument(ftName))
+
+	return err
+}
+
+func optionalTokenContract(token *FungibleTokenContract) (cadence.Optional, cadence.Optional) {
+	if token == nil {
+		return cadence.NewOptional(nil), cadence.NewOptional(nil)
+	}
+	return cadence.NewOptional(cadence.NewAddress(token.Address)), cadence.NewOptional(cadence.String(token.Name))
+}
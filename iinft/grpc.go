@@ -23,6 +23,20 @@ func NewGrpcClient(baseLoader flowkit.ReaderWriter, network string, opts ...grpc
 		return nil, err
 	}
 
+	return dialGrpcClient(networkDef.Host, opts...)
+}
+
+// NewGrpcClientWithConfig works like NewGrpcClient, but takes the access node host
+// from the given configuration instead of flow.json.
+func NewGrpcClientWithConfig(cfg *Config, opts ...grpcAccess.ClientOption) (access.Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return dialGrpcClient(cfg.Host, opts...)
+}
+
+func dialGrpcClient(host string, opts ...grpcAccess.ClientOption) (access.Client, error) {
 	options := append(
 		[]grpcAccess.ClientOption{
 			grpcAccess.WithGRPCDialOptions(
@@ -33,12 +47,12 @@ func NewGrpcClient(baseLoader flowkit.ReaderWriter, network string, opts ...grpc
 	)
 
 	gClient, err := grpcAccess.NewClient(
-		networkDef.Host,
+		host,
 		options...,
 	)
 
 	if err != nil || gClient == nil {
-		return nil, fmt.Errorf("failed to connect to host %s", networkDef.Host)
+		return nil, fmt.Errorf("failed to connect to host %s", host)
 	}
 
 	return gClient, nil
@@ -0,0 +1,88 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// CheckpointStore persists the height of the last block processed by the indexer.
+	CheckpointStore interface {
+		// Load returns the last processed height. If no checkpoint was saved yet,
+		// found is false.
+		Load(ctx context.Context) (height uint64, found bool, err error)
+		// Save records the last processed height.
+		Save(ctx context.Context, height uint64) error
+	}
+
+	// MemoryCheckpointStore keeps the checkpoint in memory.
+	MemoryCheckpointStore struct {
+		height uint64
+		found  bool
+		mutex  sync.Mutex
+	}
+
+	// FileCheckpointStore keeps the checkpoint in a text file.
+	FileCheckpointStore struct {
+		path string
+	}
+)
+
+// NewMemoryCheckpointStore creates a new in-memory checkpoint store.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{}
+}
+
+func (s *MemoryCheckpointStore) Load(_ context.Context) (uint64, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.height, s.found, nil
+}
+
+func (s *MemoryCheckpointStore) Save(_ context.Context, height uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.height = height
+	s.found = true
+
+	return nil
+}
+
+// NewFileCheckpointStore creates a new checkpoint store backed by the given file.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+func (s *FileCheckpointStore) Load(_ context.Context) (uint64, bool, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	height, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return height, true, nil
+}
+
+func (s *FileCheckpointStore) Save(_ context.Context, height uint64) error {
+	// write to a temporary file first to avoid leaving a partially written checkpoint
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(strconv.FormatUint(height, 10)), 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Clean(s.path))
+}
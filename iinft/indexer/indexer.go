@@ -0,0 +1,222 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultBatchSize is the maximum height range access nodes accept in GetEventsForHeightRange.
+	DefaultBatchSize = 250
+	// DefaultPollInterval is the interval between polls when the indexer has caught up with the chain.
+	DefaultPollInterval = 2 * time.Second
+)
+
+type (
+	// Event is a decoded DigitalArt or SequelMarketplace event with its position in the chain.
+	Event struct {
+		Type             string
+		BlockID          flow.Identifier
+		BlockHeight      uint64
+		BlockTimestamp   time.Time
+		TransactionID    flow.Identifier
+		TransactionIndex int
+		EventIndex       int
+		// Payload is one of the typed event structures defined in iinft package,
+		// i.e. *iinft.MintedEvent or *iinft.TokenListedEvent.
+		Payload any
+	}
+
+	// Sink receives events in chain order. Events for the given height range
+	// are delivered in a single call. If Handle returns an error, the range
+	// will be delivered again in the next iteration, so sinks should be idempotent.
+	Sink interface {
+		Handle(ctx context.Context, startHeight, endHeight uint64, events []*Event) error
+	}
+
+	// SinkFunc is an adapter to allow the use of ordinary functions as sinks.
+	SinkFunc func(ctx context.Context, startHeight, endHeight uint64, events []*Event) error
+
+	Options struct {
+		// StartHeight is the first height to process if there is no checkpoint.
+		StartHeight uint64
+		// BatchSize is the maximum number of blocks processed in one iteration.
+		BatchSize uint64
+		// Confirmations is the number of sealed blocks the indexer stays behind
+		// the latest sealed block.
+		Confirmations uint64
+		// PollInterval is the interval between polls when the indexer has caught up.
+		PollInterval time.Duration
+		// DeadLetter receives events that can't be decoded, with the raw flow.Event as the payload.
+		// Such events are logged and skipped, so that they don't block the indexer.
+		DeadLetter func(ctx context.Context, ev *Event, err error)
+	}
+
+	// Indexer reads DigitalArt and SequelMarketplace events, block range by block range,
+	// and sends them to the sink. Only sealed blocks are processed,
+	// so the indexer never observes events that may be rolled back.
+	Indexer struct {
+		source      EventSource
+		decoder     *iinft.EventDecoder
+		checkpoints CheckpointStore
+		sink        Sink
+		opts        Options
+	}
+)
+
+func (f SinkFunc) Handle(ctx context.Context, startHeight, endHeight uint64, events []*Event) error {
+	return f(ctx, startHeight, endHeight, events)
+}
+
+// New creates a new Indexer.
+func New(source EventSource, decoder *iinft.EventDecoder, checkpoints CheckpointStore, sink Sink, opts Options) *Indexer {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	return &Indexer{
+		source:      source,
+		decoder:     decoder,
+		checkpoints: checkpoints,
+		sink:        sink,
+		opts:        opts,
+	}
+}
+
+// Run processes events until the context is cancelled.
+func (ix *Indexer) Run(ctx context.Context) error {
+	for {
+		caughtUp, err := ix.Step(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			log.Error().Err(err).Msg("Failed to process events")
+			caughtUp = true
+		}
+
+		if caughtUp {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(ix.opts.PollInterval):
+			}
+		}
+	}
+}
+
+// Step processes the next range of blocks. It returns true if there are no more
+// sealed blocks to process at the moment.
+func (ix *Indexer) Step(ctx context.Context) (bool, error) {
+	startHeight, err := ix.nextHeight(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	header, err := ix.source.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return false, err
+	}
+
+	if header.Height < ix.opts.Confirmations || header.Height-ix.opts.Confirmations < startHeight {
+		return true, nil
+	}
+	safeHeight := header.Height - ix.opts.Confirmations
+
+	endHeight := startHeight + ix.opts.BatchSize - 1
+	if endHeight > safeHeight {
+		endHeight = safeHeight
+	}
+
+	events, err := ix.fetchEvents(ctx, startHeight, endHeight)
+	if err != nil {
+		return false, err
+	}
+
+	if err = ix.sink.Handle(ctx, startHeight, endHeight, events); err != nil {
+		return false, err
+	}
+
+	if err = ix.checkpoints.Save(ctx, endHeight); err != nil {
+		return false, err
+	}
+
+	log.Debug().Uint64("start", startHeight).Uint64("end", endHeight).Int("events", len(events)).
+		Msg("Processed block range")
+
+	return endHeight == safeHeight, nil
+}
+
+func (ix *Indexer) nextHeight(ctx context.Context) (uint64, error) {
+	height, found, err := ix.checkpoints.Load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return ix.opts.StartHeight, nil
+	}
+
+	return height + 1, nil
+}
+
+func (ix *Indexer) fetchEvents(ctx context.Context, startHeight, endHeight uint64) ([]*Event, error) {
+	var res []*Event
+	for _, eventType := range ix.decoder.EventTypes() {
+		blockEvents, err := ix.source.GetEventsForHeightRange(ctx, eventType, startHeight, endHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, be := range blockEvents {
+			for _, ev := range be.Events {
+				event := &Event{
+					Type:             ev.Type,
+					BlockID:          be.BlockID,
+					BlockHeight:      be.Height,
+					BlockTimestamp:   be.BlockTimestamp,
+					TransactionID:    ev.TransactionID,
+					TransactionIndex: ev.TransactionIndex,
+					EventIndex:       ev.EventIndex,
+				}
+
+				payload, err := ix.decoder.Decode(ev)
+				if err != nil {
+					log.Warn().Err(err).Str("type", ev.Type).Uint64("height", be.Height).
+						Str("tx", ev.TransactionID.String()).Int("index", ev.EventIndex).
+						Msg("Skipping event that can't be decoded")
+					if ix.opts.DeadLetter != nil {
+						event.Payload = ev
+						ix.opts.DeadLetter(ctx, event, err)
+					}
+					continue
+				}
+
+				event.Payload = payload
+				res = append(res, event)
+			}
+		}
+	}
+
+	// events of different types are fetched separately, restore chain order
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if a.BlockHeight != b.BlockHeight {
+			return a.BlockHeight < b.BlockHeight
+		}
+		if a.TransactionIndex != b.TransactionIndex {
+			return a.TransactionIndex < b.TransactionIndex
+		}
+		return a.EventIndex < b.EventIndex
+	})
+
+	return res, nil
+}
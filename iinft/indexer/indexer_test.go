@@ -0,0 +1,272 @@
+package indexer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	. "github.com/piprate/sequel-flow-contracts/iinft/indexer"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.Stamp})
+}
+
+type rangeRequest struct {
+	start, end uint64
+}
+
+type fakeSource struct {
+	latestHeight uint64
+	requests     []rangeRequest
+	events       []flow.BlockEvents
+}
+
+func (s *fakeSource) GetLatestBlockHeader(_ context.Context, _ bool) (*flow.BlockHeader, error) {
+	return &flow.BlockHeader{Height: s.latestHeight}, nil
+}
+
+func (s *fakeSource) GetEventsForHeightRange(_ context.Context, eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error) {
+	s.requests = append(s.requests, rangeRequest{startHeight, endHeight})
+
+	var res []flow.BlockEvents
+	for _, be := range s.events {
+		if be.Height < startHeight || be.Height > endHeight {
+			continue
+		}
+		var events []flow.Event
+		for _, ev := range be.Events {
+			if ev.Type == eventType {
+				events = append(events, ev)
+			}
+		}
+		if len(events) > 0 {
+			res = append(res, flow.BlockEvents{Height: be.Height, Events: events})
+		}
+	}
+
+	return res, nil
+}
+
+func TestIndexer_Step(t *testing.T) {
+	client, err := iinft.NewNetworkConnectorEmbedded("testnet")
+	require.NoError(t, err)
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	source := &fakeSource{latestHeight: 120}
+	checkpoints := NewMemoryCheckpointStore()
+
+	var ranges []rangeRequest
+	sink := SinkFunc(func(_ context.Context, startHeight, endHeight uint64, _ []*Event) error {
+		ranges = append(ranges, rangeRequest{startHeight, endHeight})
+		return nil
+	})
+
+	ix := New(source, iinft.NewEventDecoder(se), checkpoints, sink, Options{
+		StartHeight:   100,
+		BatchSize:     8,
+		Confirmations: 5,
+	})
+
+	caughtUp, err := ix.Step(ctx)
+	require.NoError(t, err)
+	assert.False(t, caughtUp)
+
+	caughtUp, err = ix.Step(ctx)
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+
+	// nothing to do until new blocks are sealed
+
+	caughtUp, err = ix.Step(ctx)
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+
+	assert.Equal(t, []rangeRequest{{100, 107}, {108, 115}}, ranges)
+
+	height, found, err := checkpoints.Load(ctx)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(115), height)
+
+	// every event type is requested for every range
+
+	assert.Len(t, source.requests, 2*len(iinft.NewEventDecoder(se).EventTypes()))
+}
+
+func TestIndexer_DeadLetter(t *testing.T) {
+	client, err := iinft.NewNetworkConnectorEmbedded("testnet")
+	require.NoError(t, err)
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	mintedType := iinft.EventTypeID(se.ContractAddress("DigitalArt"), "DigitalArt", "Minted")
+	source := &fakeSource{
+		latestHeight: 110,
+		events: []flow.BlockEvents{
+			{Height: 102, Events: []flow.Event{{Type: mintedType, Value: cadence.Event{}}}},
+		},
+	}
+	checkpoints := NewMemoryCheckpointStore()
+
+	var delivered []*Event
+	sink := SinkFunc(func(_ context.Context, _, _ uint64, events []*Event) error {
+		delivered = append(delivered, events...)
+		return nil
+	})
+
+	var deadLetters []*Event
+	ix := New(source, iinft.NewEventDecoder(se), checkpoints, sink, Options{
+		StartHeight: 100,
+		DeadLetter: func(_ context.Context, ev *Event, err error) {
+			assert.Error(t, err)
+			deadLetters = append(deadLetters, ev)
+		},
+	})
+
+	caughtUp, err := ix.Step(ctx)
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+
+	assert.Empty(t, delivered)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, uint64(102), deadLetters[0].BlockHeight)
+	assert.IsType(t, flow.Event{}, deadLetters[0].Payload)
+
+	height, _, err := checkpoints.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(110), height)
+}
+
+func TestIndexer_Emulator(t *testing.T) {
+	client, err := iinft.NewInMemoryConnectorEmbedded(false)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = client.CreateAccountsE(ctx, "emulator-account")
+	require.NoError(t, err)
+
+	err = client.InitializeContractsE(ctx)
+	require.NoError(t, err)
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, "sequel-admin")
+
+	userAcct := client.Account("user1")
+
+	_, err = se.NewTransaction("account_setup").SignProposeAndPayAs("user1").RunE(ctx)
+	require.NoError(t, err)
+
+	metadata := &iinft.DigitalArtMetadata{
+		Name:       "Pure Art",
+		Artist:     "did:sequel:artist",
+		Type:       "Image",
+		MaxEdition: 2,
+		Asset:      "did:sequel:asset-id",
+	}
+	profile := &evergreen.Profile{
+		ID: "did:sequel:evergreen1",
+		Roles: []*evergreen.Role{
+			{
+				ID:                        evergreen.RoleArtist,
+				InitialSaleCommission:     evergreen.MustParseUFix64("1.0"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
+				Address:                   userAcct.Address,
+			},
+		},
+	}
+
+	require.NoError(t, c.SealMaster(ctx, metadata, profile))
+
+	nftIDs, err := c.MintEdition(ctx, metadata.Asset, 2, userAcct.Address)
+	require.NoError(t, err)
+
+	checkpoints := NewMemoryCheckpointStore()
+
+	var events []*Event
+	sink := SinkFunc(func(_ context.Context, _, _ uint64, batch []*Event) error {
+		events = append(events, batch...)
+		return nil
+	})
+
+	ix := New(NewConnectorSource(client), iinft.NewEventDecoder(se), checkpoints, sink, Options{
+		BatchSize: 3,
+	})
+
+	for {
+		caughtUp, err := ix.Step(ctx)
+		require.NoError(t, err)
+		if caughtUp {
+			break
+		}
+	}
+
+	require.Len(t, events, 4)
+
+	// events are delivered in chain order
+
+	for i, ev := range events {
+		switch i % 2 {
+		case 0:
+			minted, ok := ev.Payload.(*iinft.MintedEvent)
+			require.True(t, ok)
+			assert.Equal(t, nftIDs[i/2], minted.ID)
+			assert.Equal(t, uint64(i/2+1), minted.Edition)
+		case 1:
+			deposit, ok := ev.Payload.(*iinft.DepositEvent)
+			require.True(t, ok)
+			assert.Equal(t, nftIDs[i/2], deposit.ID)
+			assert.Equal(t, &userAcct.Address, deposit.To)
+		}
+		assert.NotZero(t, ev.BlockHeight)
+		assert.NotEqual(t, flow.EmptyID, ev.TransactionID)
+	}
+
+	// restarting from the checkpoint doesn't deliver the same events again
+
+	events = nil
+
+	ix = New(NewConnectorSource(client), iinft.NewEventDecoder(se), checkpoints, sink, Options{})
+
+	caughtUp, err := ix.Step(ctx)
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+	assert.Empty(t, events)
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint"))
+
+	_, found, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.Save(ctx, 42))
+
+	height, found, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(42), height)
+}
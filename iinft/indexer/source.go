@@ -0,0 +1,42 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit/v2"
+	"github.com/piprate/splash"
+)
+
+// EventSource is the subset of access.Client API used by the indexer.
+// Any access.Client (i.e. the one returned by iinft.NewGrpcClient) satisfies this interface.
+type EventSource interface {
+	// GetLatestBlockHeader returns the latest sealed or unsealed block header.
+	GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.BlockHeader, error)
+	// GetEventsForHeightRange returns events for all sealed blocks between the start and end block heights (inclusive) with the given type.
+	GetEventsForHeightRange(ctx context.Context, eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error)
+}
+
+type connectorSource struct {
+	client *splash.Connector
+}
+
+// NewConnectorSource creates an event source that reads events via the given Splash connector.
+// This is useful for connectors that don't expose a gRPC client, such as the in-memory emulator
+// created by iinft.NewInMemoryConnectorEmbedded.
+func NewConnectorSource(client *splash.Connector) EventSource {
+	return &connectorSource{client: client}
+}
+
+func (s *connectorSource) GetLatestBlockHeader(ctx context.Context, _ bool) (*flow.BlockHeader, error) {
+	block, err := s.client.Services.GetBlock(ctx, flowkit.LatestBlockQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return &block.BlockHeader, nil
+}
+
+func (s *connectorSource) GetEventsForHeightRange(ctx context.Context, eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error) {
+	return s.client.Services.GetEvents(ctx, []string{eventType}, startHeight, endHeight, nil)
+}
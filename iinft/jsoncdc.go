@@ -0,0 +1,31 @@
+package iinft
+
+import (
+	"errors"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// MarshalJSONCDC encodes the metadata as a JSON-Cadence value of DigitalArt.Metadata type,
+// i.e. to be used with `flow transactions send --args-json`.
+func (m *DigitalArtMetadata) MarshalJSONCDC(digitalArtAddr flow.Address) ([]byte, error) {
+	return jsoncdc.Encode(DigitalArtMetadataToCadence(m, digitalArtAddr))
+}
+
+// UnmarshalJSONCDC decodes a JSON-Cadence value of DigitalArt.Metadata type.
+func (m *DigitalArtMetadata) UnmarshalJSONCDC(data []byte) error {
+	val, err := jsoncdc.Decode(nil, data)
+	if err != nil {
+		return err
+	}
+	metadata, err := DigitalArtMetadataFromCadence(val)
+	if err != nil {
+		return err
+	}
+	if metadata == nil {
+		return errors.New("bad Metadata value")
+	}
+	*m = *metadata
+	return nil
+}
@@ -0,0 +1,25 @@
+package iinft_test
+
+import (
+	"testing"
+
+	. "github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigitalArtMetadata_JSONCDC(t *testing.T) {
+	metadata := sampleMetadata()
+
+	data, err := metadata.MarshalJSONCDC(sequelAddress)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"id":"A.179b6b1cb6755e31.DigitalArt.Metadata"`)
+
+	var res DigitalArtMetadata
+	require.NoError(t, res.UnmarshalJSONCDC(data))
+	assert.Equal(t, metadata, &res)
+
+	assert.Error(t, res.UnmarshalJSONCDC([]byte(`{"type":"String","value":"metadata"}`)))
+	assert.Error(t, res.UnmarshalJSONCDC([]byte(`{"type":"Optional","value":null}`)))
+	assert.Error(t, res.UnmarshalJSONCDC([]byte(`not json`)))
+}
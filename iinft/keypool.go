@@ -0,0 +1,304 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	fvmerrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/onflow/flowkit/v2/transactions"
+	"github.com/piprate/splash"
+)
+
+// maxSendAttempts limits how many times KeyPool.Run sends a transaction that was rejected
+// due to a stale sequence number or an expired reference block.
+const maxSendAttempts = 10
+
+// retryDelay is the base delay before a rejected transaction is resent. The actual delay
+// is randomised and grows with each attempt, so that concurrent transactions that
+// were rejected together don't collide again.
+const retryDelay = 50 * time.Millisecond
+
+var ErrEmptyKeyPool = errors.New("key pool is empty")
+
+type (
+	// KeyPool allocates proposal keys of a single account to concurrent transactions.
+	// Flow transactions from the same proposal key must be sent one after another,
+	// because each of them uses the next sequence number of the key. The pool gives
+	// each transaction exclusive use of one of the account's keys until the transaction
+	// is sealed, and tracks the keys' sequence numbers.
+	//
+	// All keys must have full weight, as each key signs transactions on its own.
+	// Use Client.AddProposalKeys to add copies of an existing key to the account.
+	KeyPool struct {
+		client  *splash.Connector
+		address flow.Address
+		free    chan *poolKey
+	}
+
+	poolKey struct {
+		account *accounts.Account
+		seq     uint64
+		synced  bool
+	}
+)
+
+// NewKeyPool creates a pool of the given account keys.
+func NewKeyPool(client *splash.Connector, address flow.Address, keys []accounts.Key) (*KeyPool, error) {
+	if len(keys) == 0 {
+		return nil, ErrEmptyKeyPool
+	}
+
+	p := &KeyPool{
+		client:  client,
+		address: address,
+		free:    make(chan *poolKey, len(keys)),
+	}
+
+	for _, key := range keys {
+		p.free <- &poolKey{
+			account: &accounts.Account{
+				Name:    fmt.Sprintf("%s#%d", address.Hex(), key.Index()),
+				Address: address,
+				Key:     key,
+			},
+		}
+	}
+
+	return p, nil
+}
+
+// CloneKey returns a copy of the given key with another key index. It supports keys
+// with accessible private keys (i.e. in-memory and file keys) and remote keys.
+func CloneKey(key accounts.Key, index uint32) (accounts.Key, error) {
+	if remoteKey, ok := key.(*RemoteKey); ok {
+		opts := remoteKey.opts
+		opts.Index = index
+		return NewRemoteKey(remoteKey.url, remoteKey.publicKey, opts, remoteKey.httpClient), nil
+	}
+
+	privateKey, err := key.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts.NewHexKeyFromPrivateKey(index, key.HashAlgo(), *privateKey), nil
+}
+
+// Address returns the address of the account that owns the keys.
+func (p *KeyPool) Address() flow.Address {
+	return p.address
+}
+
+// Size returns the number of keys in the pool.
+func (p *KeyPool) Size() int {
+	return cap(p.free)
+}
+
+// Run sends the transaction with a key from the pool as the proposer, payer and authorizer.
+// Payload signers set in the transaction builder sign the transaction too. If all keys
+// are busy, Run waits until a key is released or the context is cancelled. If the transaction
+// is rejected due to a sequence number mismatch (i.e. the key was used outside the pool)
+// or an expired reference block, the key's sequence number is fetched from the chain
+// and the transaction is rebuilt and resent after a short delay.
+func (p *KeyPool) Run(ctx context.Context, tb splash.FlowTransactionBuilder) (*flow.TransactionResult, error) {
+	var key *poolKey
+	select {
+	case key = <-p.free:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() {
+		p.free <- key
+	}()
+
+	for attempt := 1; ; attempt++ {
+		if !key.synced {
+			if err := p.sync(ctx, key); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := p.send(ctx, tb, key)
+		if err != nil && attempt < maxSendAttempts && isRejectedTransactionError(err) {
+			key.synced = false
+
+			select {
+			case <-time.After(time.Duration(rand.Int64N(int64(retryDelay) * int64(attempt)))):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		return res, err
+	}
+}
+
+func (p *KeyPool) sync(ctx context.Context, key *poolKey) error {
+	acct, err := p.client.Services.GetAccount(ctx, p.address)
+	if err != nil {
+		return err
+	}
+
+	index := key.account.Key.Index()
+	for _, k := range acct.Keys {
+		if k.Index == index {
+			if k.Revoked {
+				return fmt.Errorf("key %d of account %s is revoked", index, p.address)
+			}
+			key.seq = k.SequenceNumber
+			key.synced = true
+			return nil
+		}
+	}
+
+	return fmt.Errorf("key %d not found in account %s", index, p.address)
+}
+
+// send works like splash.FlowTransactionBuilder.RunE, but uses the tracked sequence
+// number of the pool key instead of the one reported by the access node.
+func (p *KeyPool) send(ctx context.Context, tb splash.FlowTransactionBuilder, key *poolKey) (*flow.TransactionResult, error) {
+	tx, err := buildSignedTransaction(ctx, tb, key.account, &key.seq)
+	if err != nil {
+		return nil, err
+	}
+
+	_, res, err := tb.Connector.Services.SendSignedTransaction(ctx, tx)
+	if err != nil {
+		// the transaction may or may not have been accepted
+		key.synced = false
+		return nil, err
+	}
+
+	if res.Error != nil {
+		if isSequenceNumberError(res.Error) {
+			key.synced = false
+		} else {
+			// failed transactions still increment the proposer's sequence number
+			key.seq++
+		}
+		return nil, res.Error
+	}
+
+	key.seq++
+
+	return res, nil
+}
+
+// buildSignedTransaction builds the transaction with the given account as the proposer, payer
+// and the last authorizer, and signs it with the payload signers set in the transaction builder
+// and the account's key. If seq is not nil, it overrides the sequence number of the proposal key
+// reported by the access node.
+func buildSignedTransaction(ctx context.Context, tb splash.FlowTransactionBuilder, account *accounts.Account, seq *uint64) (*transactions.Transaction, error) {
+	authorizers := make([]flow.Address, 0, len(tb.PayloadSigners)+1)
+	signers := make([]*accounts.Account, 0, len(tb.PayloadSigners)+1)
+	for _, signer := range tb.PayloadSigners {
+		authorizers = append(authorizers, signer.Address)
+		if signer.Address != account.Address {
+			signers = append(signers, signer)
+		}
+	}
+	authorizers = append(authorizers, account.Address)
+	// the payer signs last
+	signers = append(signers, account)
+
+	tx, err := tb.Connector.Services.BuildTransaction(
+		ctx,
+		transactions.AddressesRoles{
+			Proposer:    account.Address,
+			Authorizers: authorizers,
+			Payer:       account.Address,
+		},
+		account.Key.Index(),
+		flowkit.Script{
+			Code:     []byte(tb.Content),
+			Args:     tb.Arguments,
+			Location: fmt.Sprintf("./transactions/%s.cdc", tb.FileName),
+		},
+		tb.GasLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if seq != nil {
+		tx.FlowTransaction().SetProposalKey(account.Address, account.Key.Index(), *seq)
+	}
+
+	for _, signer := range signers {
+		if err = tx.SetSigner(signer); err != nil {
+			return nil, err
+		}
+		if tx, err = tx.Sign(); err != nil {
+			return nil, err
+		}
+	}
+
+	return tx, nil
+}
+
+func isSequenceNumberError(err error) bool {
+	return hasFVMErrorCode(err, fvmerrors.ErrCodeInvalidProposalSeqNumberError)
+}
+
+// isRejectedTransactionError reports if the transaction was rejected without being executed,
+// so it can be safely resent.
+func isRejectedTransactionError(err error) bool {
+	return isSequenceNumberError(err) || strings.Contains(err.Error(), "transaction is expired")
+}
+
+// WithKeyPool returns a copy of the client that sends transactions signed by the admin account
+// with keys from the given pool, so that they can run concurrently. It returns an error
+// if the pool's address doesn't match the admin account's address.
+func (c *Client) WithKeyPool(pool *KeyPool) (*Client, error) {
+	if adminAddress := c.client.Account(c.adminAccount).Address; pool.Address() != adminAddress {
+		return nil, fmt.Errorf("key pool address %s doesn't match admin account address %s", pool.Address(), adminAddress)
+	}
+
+	res := *c
+	res.keyPool = pool
+	return &res, nil
+}
+
+// AddProposalKeys adds count copies of the admin account's key with the given index
+// to the account. It returns the indexes of the new keys. Use CloneKey and NewKeyPool
+// to create a key pool with the new keys.
+func (c *Client) AddProposalKeys(ctx context.Context, keyIndex uint32, count int) ([]uint32, error) {
+	res, err := c.runAsAdmin(ctx, c.se.NewTransaction("account_add_proposal_keys").
+		Argument(cadence.NewInt(int(keyIndex))).
+		Argument(cadence.NewInt(count)))
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []uint32
+	for _, evt := range res.Events {
+		if evt.Type != flow.EventAccountKeyAdded {
+			continue
+		}
+		index, ok := evt.Value.FieldsMappedByName()["keyIndex"].(cadence.Int)
+		if !ok {
+			return nil, errors.New("bad keyIndex value")
+		}
+		indexes = append(indexes, uint32(index.Int()))
+	}
+
+	return indexes, nil
+}
+
+// runAsAdmin sends the transaction signed by the admin account, using the key pool if set.
+func (c *Client) runAsAdmin(ctx context.Context, tb splash.FlowTransactionBuilder) (*flow.TransactionResult, error) {
+	if c.keyPool != nil {
+		return c.keyPool.Run(ctx, tb)
+	}
+
+	return tb.SignProposeAndPayAs(c.adminAccount).RunE(ctx)
+}
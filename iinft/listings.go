@@ -0,0 +1,228 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+)
+
+// Reasons why a listing is considered stale, as reported by SequelMarketplace.getStaleReason.
+const (
+	StaleReasonPurchased = "purchased"
+	StaleReasonExpired   = "expired"
+	// StaleReasonGhost means the listed token is no longer in the seller's collection.
+	StaleReasonGhost = "ghost"
+)
+
+// DefaultCleanupBatchSize is the number of listings checked by a single script
+// or removed by a single transaction, unless CleanupOptions.BatchSize is set.
+const DefaultCleanupBatchSize = 100
+
+type (
+	// StaleListing is a storefront listing that can't be purchased anymore.
+	StaleListing struct {
+		ListingID uint64
+		// Reason is one of StaleReason* constants.
+		Reason string
+	}
+
+	// CleanupOptions controls how CleanupStaleListings splits listings into transactions.
+	CleanupOptions struct {
+		// BatchSize is the maximum number of listings in a single script or transaction.
+		// If zero, DefaultCleanupBatchSize is used.
+		BatchSize int
+		// Signer is the name of the account that pays for cleanup transactions.
+		// If empty, the client's admin account is used.
+		Signer string
+	}
+
+	// ListingQuote mirrors SequelMarketplace.PaymentQuote structure. It shows how the proceeds
+	// of a sale will be distributed, if the token is sold at the given price.
+	ListingQuote struct {
+		Price evergreen.UFix64
+		// SellerProceeds is the total amount paid to the seller. It's zero, if the commissions
+		// in the token's Evergreen profile and platform fees reach 100%.
+		SellerProceeds evergreen.UFix64
+		// Royalties are the payments to parties other than the seller.
+		Royalties []*evergreen.Payment
+		// SkippedRoyalties are the payments that won't be made, because the receiver
+		// doesn't have a fungible token receiver capability. Their share goes to the seller.
+		SkippedRoyalties []*evergreen.Payment
+	}
+)
+
+// GetStaleListings returns purchased, expired and ghost listings in the given storefront,
+// ordered by listing ID. Listings are checked in batches of batchSize
+// (DefaultCleanupBatchSize if zero) to stay within script computation limits.
+func (c *Client) GetStaleListings(ctx context.Context, storefront flow.Address, batchSize int) ([]*StaleListing, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultCleanupBatchSize
+	}
+
+	val, err := c.se.NewScript("marketplace_get_listing_ids").
+		Argument(cadence.NewAddress(storefront)).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := val.(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad marketplace_get_listing_ids result")
+	}
+
+	var res []*StaleListing
+	for start := 0; start < len(arr.Values); start += batchSize {
+		end := min(start+batchSize, len(arr.Values))
+
+		val, err = c.se.NewScript("marketplace_get_stale_listings").
+			Argument(cadence.NewAddress(storefront)).
+			Argument(cadence.NewArray(arr.Values[start:end])).
+			RunReturns(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		dict, ok := val.(cadence.Dictionary)
+		if !ok {
+			return nil, errors.New("bad marketplace_get_stale_listings result")
+		}
+		for _, pair := range dict.Pairs {
+			id, ok := pair.Key.(cadence.UInt64)
+			if !ok {
+				return nil, errors.New("bad listing ID")
+			}
+			reason, ok := pair.Value.(cadence.String)
+			if !ok {
+				return nil, errors.New("bad stale reason")
+			}
+			res = append(res, &StaleListing{ListingID: uint64(id), Reason: string(reason)})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ListingID < res[j].ListingID
+	})
+
+	return res, nil
+}
+
+// CleanupStaleListings finds stale listings in the given storefront and removes them,
+// opts.BatchSize listings per transaction. Expired and ghost listings can only be removed
+// if the seller has listed tokens with "marketplace_list*" templates, which register
+// a cleanup capability with SequelMarketplace. CleanupStaleListings returns the listings
+// that were removed. If a transaction fails, it returns the listings removed so far
+// and the error.
+func (c *Client) CleanupStaleListings(ctx context.Context, storefront flow.Address, opts CleanupOptions) ([]*ListingCleanedUpEvent, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultCleanupBatchSize
+	}
+	signer := opts.Signer
+	if signer == "" {
+		signer = c.adminAccount
+	}
+
+	stale, err := c.GetStaleListings(ctx, storefront, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*ListingCleanedUpEvent
+	for start := 0; start < len(stale); start += batchSize {
+		end := min(start+batchSize, len(stale))
+
+		ids := make([]cadence.Value, 0, end-start)
+		for _, l := range stale[start:end] {
+			ids = append(ids, cadence.UInt64(l.ListingID))
+		}
+
+		txRes, err := c.se.NewTransaction("marketplace_cleanup").
+			SignProposeAndPayAs(signer).
+			Argument(cadence.NewAddress(storefront)).
+			Argument(cadence.NewArray(ids)).
+			RunE(ctx)
+		if err != nil {
+			return res, err
+		}
+
+		cleaned, err := c.decoder.ListingCleanedUpEvents(txRes.Events)
+		if err != nil {
+			return res, err
+		}
+		res = append(res, cleaned...)
+	}
+
+	return res, nil
+}
+
+// ListingQuoteFromCadence decodes SequelMarketplace.PaymentQuote value.
+func ListingQuoteFromCadence(val cadence.Value) (*ListingQuote, error) {
+	valStruct, ok := val.(cadence.Struct)
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "SequelMarketplace.PaymentQuote" {
+		return nil, errors.New("not a SequelMarketplace.PaymentQuote value")
+	}
+
+	fields, err := structFields(valStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var res ListingQuote
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+	if res.SellerProceeds, err = ufix64Field(fields, "sellerProceeds"); err != nil {
+		return nil, err
+	}
+	if res.Royalties, err = paymentsField(fields, "royalties"); err != nil {
+		return nil, err
+	}
+	if res.SkippedRoyalties, err = paymentsField(fields, "skippedRoyalties"); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// QuoteListing returns the seller proceeds and royalties for the given token, if it's listed
+// with ListToken at the given price and sold. Sellers should check the quote before listing:
+// roles without a receiver capability for the token are skipped, and if the commissions
+// reach 100%, the seller receives nothing.
+func (c *Client) QuoteListing(ctx context.Context, seller flow.Address, tokenID uint64, price string, token FungibleTokenContract) (*ListingQuote, error) {
+	val, err := c.se.NewScript("marketplace_quote_listing").
+		Argument(cadence.NewAddress(seller)).
+		UInt64Argument(tokenID).
+		UFix64Argument(price).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListingQuoteFromCadence(val)
+}
+
+func paymentsField(fields map[string]cadence.Value, name string) ([]*evergreen.Payment, error) {
+	arr, ok := fields[name].(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("bad %s value", name)
+	}
+
+	res := make([]*evergreen.Payment, 0, len(arr.Values))
+	for _, v := range arr.Values {
+		payment, err := PaymentFromCadence(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, payment)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,133 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+
+	"github.com/onflow/cadence"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+)
+
+// Master mirrors DigitalArt.Master structure, as returned by "digitalart_get_masters" script.
+type Master struct {
+	// AssetID is the ID of the master, the same as Metadata.Asset.
+	AssetID string
+	// Metadata and Profile are nil, if the master is closed.
+	Metadata *DigitalArtMetadata
+	Profile  *evergreen.Profile
+	// NextEdition is the edition number of the next NFT minted from the master.
+	// It's zero, if the master is closed.
+	NextEdition uint64
+	Closed      bool
+	Paused      bool
+}
+
+// AvailableEditions mirrors DigitalArt.Master.availableEditions function.
+// Paused masters still report their available editions.
+func (m *Master) AvailableEditions() uint64 {
+	if !m.Closed && m.Metadata != nil && m.Metadata.MaxEdition >= m.NextEdition {
+		return m.Metadata.MaxEdition - m.NextEdition + 1
+	}
+	return 0
+}
+
+// MasterFromCadence decodes a MasterState value returned by "digitalart_get_masters" script.
+func MasterFromCadence(val cadence.Value) (*Master, error) {
+	stateStruct, ok := val.(cadence.Struct)
+	if !ok || stateStruct.StructType == nil {
+		return nil, errors.New("bad MasterState value")
+	}
+
+	stateFields, err := structFields(stateStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, ok := stateFields["asset"].(cadence.String)
+	if !ok {
+		return nil, errors.New("bad MasterState value: asset")
+	}
+	paused, ok := stateFields["paused"].(cadence.Bool)
+	if !ok {
+		return nil, errors.New("bad MasterState value: paused")
+	}
+
+	masterStruct, ok := stateFields["master"].(cadence.Struct)
+	if !ok || masterStruct.StructType == nil || masterStruct.StructType.QualifiedIdentifier != "DigitalArt.Master" {
+		return nil, errors.New("not a DigitalArt.Master value")
+	}
+
+	fields, err := structFields(masterStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Master{
+		AssetID: string(asset),
+		Paused:  bool(paused),
+	}
+
+	if res.Metadata, err = DigitalArtMetadataFromCadence(fields["metadata"]); err != nil {
+		return nil, err
+	}
+	if res.Profile, err = evergreen.ProfileFromCadence(fields["evergreenProfile"]); err != nil {
+		return nil, err
+	}
+
+	nextEdition, ok := fields["nextEdition"].(cadence.UInt64)
+	if !ok {
+		return nil, errors.New("bad DigitalArt.Master value: nextEdition")
+	}
+	res.NextEdition = uint64(nextEdition)
+
+	closed, ok := fields["closed"].(cadence.Bool)
+	if !ok {
+		return nil, errors.New("bad DigitalArt.Master value: closed")
+	}
+	res.Closed = bool(closed)
+
+	return res, nil
+}
+
+// GetMaster returns the master with the given asset ID, or nil if the master isn't sealed.
+// It doesn't require the admin account.
+func (c *Client) GetMaster(ctx context.Context, assetID string) (*Master, error) {
+	masters, err := c.GetMasters(ctx, []string{assetID})
+	if err != nil {
+		return nil, err
+	}
+
+	return masters[assetID], nil
+}
+
+// GetMasters returns all sealed masters with the given asset IDs in a single script call.
+// Asset IDs of masters that aren't sealed are absent from the result.
+func (c *Client) GetMasters(ctx context.Context, assetIDs []string) (map[string]*Master, error) {
+	ids := make([]cadence.Value, len(assetIDs))
+	for i, id := range assetIDs {
+		ids[i] = cadence.String(id)
+	}
+
+	val, err := c.se.NewScript("digitalart_get_masters").
+		Argument(cadence.NewArray(ids)).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := val.(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad digitalart_get_masters result")
+	}
+
+	res := make(map[string]*Master, len(arr.Values))
+	for _, v := range arr.Values {
+		master, err := MasterFromCadence(v)
+		if err != nil {
+			return nil, err
+		}
+		res[master.AssetID] = master
+	}
+
+	return res, nil
+}
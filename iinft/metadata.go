@@ -0,0 +1,213 @@
+package iinft
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/flow-go-sdk"
+)
+
+const (
+	// MetadataLayoutV1 is the layout of DigitalArt.Metadata structure
+	// as it was originally deployed.
+	MetadataLayoutV1 = 1
+
+	// LatestMetadataLayout is the layout of DigitalArt.Metadata structure in the current
+	// version of DigitalArt contract.
+	LatestMetadataLayout = MetadataLayoutV1
+)
+
+type (
+	// MetadataFieldError describes a problem with a single field of DigitalArt.Metadata value.
+	MetadataFieldError struct {
+		Field   string
+		Message string
+	}
+
+	// MetadataErrors is a list of problems found when decoding DigitalArt.Metadata value.
+	MetadataErrors []*MetadataFieldError
+
+	// metadataField describes a field of DigitalArt.Metadata structure.
+	// Fields are never removed from the contract, and new fields are appended
+	// to the end of the structure, so each layout is a prefix of the next one.
+	metadataField struct {
+		name   string
+		typ    cadence.Type
+		since  int
+		decode func(m *DigitalArtMetadata, val cadence.Value) bool
+		encode func(m *DigitalArtMetadata) cadence.Value
+	}
+)
+
+func (e *MetadataFieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func (e MetadataErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "bad Metadata value: " + strings.Join(msgs, "; ")
+}
+
+var metadataFields = []*metadataField{
+	stringMetadataField("name", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.Name }),
+	stringMetadataField("artist", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.Artist }),
+	stringMetadataField("description", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.Description }),
+	stringMetadataField("type", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.Type }),
+	stringMetadataField("contentURI", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.ContentURI }),
+	stringMetadataField("contentPreviewURI", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.ContentPreviewURI }),
+	stringMetadataField("mimetype", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.ContentMimetype }),
+	uint64MetadataField("edition", MetadataLayoutV1, func(m *DigitalArtMetadata) *uint64 { return &m.Edition }),
+	uint64MetadataField("maxEdition", MetadataLayoutV1, func(m *DigitalArtMetadata) *uint64 { return &m.MaxEdition }),
+	stringMetadataField("asset", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.Asset }),
+	stringMetadataField("metadataURI", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.MetadataURI }),
+	stringMetadataField("record", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.Record }),
+	stringMetadataField("assetHead", MetadataLayoutV1, func(m *DigitalArtMetadata) *string { return &m.AssetHead }),
+}
+
+func stringMetadataField(name string, since int, ref func(m *DigitalArtMetadata) *string) *metadataField {
+	return &metadataField{
+		name:  name,
+		typ:   cadence.StringType,
+		since: since,
+		decode: func(m *DigitalArtMetadata, val cadence.Value) bool {
+			v, ok := val.(cadence.String)
+			if ok {
+				*ref(m) = string(v)
+			}
+			return ok
+		},
+		encode: func(m *DigitalArtMetadata) cadence.Value {
+			return cadence.String(*ref(m))
+		},
+	}
+}
+
+func uint64MetadataField(name string, since int, ref func(m *DigitalArtMetadata) *uint64) *metadataField {
+	return &metadataField{
+		name:  name,
+		typ:   cadence.UInt64Type,
+		since: since,
+		decode: func(m *DigitalArtMetadata, val cadence.Value) bool {
+			v, ok := val.(cadence.UInt64)
+			if ok {
+				*ref(m) = uint64(v)
+			}
+			return ok
+		},
+		encode: func(m *DigitalArtMetadata) cadence.Value {
+			return cadence.UInt64(*ref(m))
+		},
+	}
+}
+
+// DigitalArtMetadataFromCadence decodes DigitalArt.Metadata value. All fields of the original
+// metadata layout (MetadataLayoutV1) are required, fields introduced in later layouts are optional.
+// Unknown fields are ignored, so that the decoder keeps working after contract upgrades.
+// If any field is missing or has a wrong type, MetadataErrors is returned.
+func DigitalArtMetadataFromCadence(val cadence.Value) (*DigitalArtMetadata, error) {
+	res, _, err := DigitalArtMetadataFromCadenceWithLayout(val)
+	return res, err
+}
+
+// DigitalArtMetadataFromCadenceWithLayout works like DigitalArtMetadataFromCadence,
+// but also returns the latest metadata layout the given value conforms to.
+func DigitalArtMetadataFromCadenceWithLayout(val cadence.Value) (*DigitalArtMetadata, int, error) {
+	if opt, ok := val.(cadence.Optional); ok {
+		if opt.Value == nil {
+			return nil, 0, nil
+		}
+		val = opt.Value
+	}
+
+	valStruct, ok := val.(cadence.Struct)
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "DigitalArt.Metadata" {
+		return nil, 0, MetadataErrors{{Message: "not a DigitalArt.Metadata value"}}
+	}
+
+	allFields, err := structFields(valStruct)
+	if err != nil {
+		return nil, 0, MetadataErrors{{Message: err.Error()}}
+	}
+
+	var res DigitalArtMetadata
+	var errs MetadataErrors
+	layout := LatestMetadataLayout
+	for _, f := range metadataFields {
+		fieldVal, found := allFields[f.name]
+		if !found || fieldVal == nil {
+			if f.since == MetadataLayoutV1 {
+				errs = append(errs, &MetadataFieldError{Field: f.name, Message: "missing"})
+			} else if f.since <= layout {
+				layout = f.since - 1
+			}
+			continue
+		}
+
+		if !f.decode(&res, fieldVal) {
+			errs = append(errs, &MetadataFieldError{
+				Field:   f.name,
+				Message: fmt.Sprintf("expected %s, got %T", f.typ.ID(), fieldVal),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, 0, errs
+	}
+
+	return &res, layout, nil
+}
+
+// DigitalArtMetadataToCadence encodes the metadata using the latest metadata layout.
+func DigitalArtMetadataToCadence(metadata *DigitalArtMetadata, digitalArtAddr flow.Address) cadence.Value {
+	val, _ := DigitalArtMetadataToCadenceWithLayout(metadata, digitalArtAddr, LatestMetadataLayout)
+	return val
+}
+
+// DigitalArtMetadataToCadenceWithLayout encodes the metadata using the given metadata layout.
+// Use it to produce values for DigitalArt contracts that haven't been upgraded yet.
+func DigitalArtMetadataToCadenceWithLayout(metadata *DigitalArtMetadata, digitalArtAddr flow.Address, layout int) (cadence.Value, error) {
+	if layout < MetadataLayoutV1 || layout > LatestMetadataLayout {
+		return nil, fmt.Errorf("unsupported metadata layout: %d", layout)
+	}
+
+	var values []cadence.Value
+	var fields []cadence.Field
+	for _, f := range metadataFields {
+		if f.since > layout {
+			continue
+		}
+		values = append(values, f.encode(metadata))
+		fields = append(fields, cadence.Field{Identifier: f.name, Type: f.typ})
+	}
+
+	return cadence.NewStruct(values).WithType(cadence.NewStructType(
+		common.AddressLocation{
+			Address: common.Address(digitalArtAddr),
+			Name:    common.AddressLocationPrefix,
+		},
+		"DigitalArt.Metadata",
+		fields,
+		nil,
+	)), nil
+}
+
+// structFields maps struct fields by name. Unlike cadence.Struct.FieldsMappedByName,
+// it returns an error instead of panicking if the value doesn't match its type.
+func structFields(val cadence.Struct) (res map[string]cadence.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed %s value", val.StructType.QualifiedIdentifier)
+		}
+	}()
+
+	return val.FieldsMappedByName(), nil
+}
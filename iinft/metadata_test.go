@@ -0,0 +1,152 @@
+package iinft_test
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/common"
+	. "github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleMetadata() *DigitalArtMetadata {
+	return &DigitalArtMetadata{
+		MetadataURI:       "ipfs://QmMetadata",
+		Name:              "Pure Art",
+		Artist:            "did:sequel:artist",
+		Description:       "Digital art in its purest form",
+		Type:              "Image",
+		ContentURI:        "ipfs://QmContent",
+		ContentPreviewURI: "ipfs://QmPreview",
+		ContentMimetype:   "image/jpeg",
+		Edition:           2,
+		MaxEdition:        4,
+		Asset:             "did:sequel:asset-id",
+		Record:            "record-id",
+		AssetHead:         "asset-head-id",
+	}
+}
+
+// modifiedMetadataValue creates a DigitalArt.Metadata value for the sample metadata, letting fn change its fields.
+func modifiedMetadataValue(fn func(fields []cadence.Field, values []cadence.Value) ([]cadence.Field, []cadence.Value)) cadence.Value {
+	m := sampleMetadata()
+	fields := []cadence.Field{
+		{Identifier: "name", Type: cadence.StringType},
+		{Identifier: "artist", Type: cadence.StringType},
+		{Identifier: "description", Type: cadence.StringType},
+		{Identifier: "type", Type: cadence.StringType},
+		{Identifier: "contentURI", Type: cadence.StringType},
+		{Identifier: "contentPreviewURI", Type: cadence.StringType},
+		{Identifier: "mimetype", Type: cadence.StringType},
+		{Identifier: "edition", Type: cadence.UInt64Type},
+		{Identifier: "maxEdition", Type: cadence.UInt64Type},
+		{Identifier: "asset", Type: cadence.StringType},
+		{Identifier: "metadataURI", Type: cadence.StringType},
+		{Identifier: "record", Type: cadence.StringType},
+		{Identifier: "assetHead", Type: cadence.StringType},
+	}
+	values := []cadence.Value{
+		cadence.String(m.Name),
+		cadence.String(m.Artist),
+		cadence.String(m.Description),
+		cadence.String(m.Type),
+		cadence.String(m.ContentURI),
+		cadence.String(m.ContentPreviewURI),
+		cadence.String(m.ContentMimetype),
+		cadence.UInt64(m.Edition),
+		cadence.UInt64(m.MaxEdition),
+		cadence.String(m.Asset),
+		cadence.String(m.MetadataURI),
+		cadence.String(m.Record),
+		cadence.String(m.AssetHead),
+	}
+
+	fields, values = fn(fields, values)
+
+	return cadence.NewStruct(values).WithType(cadence.NewStructType(
+		common.AddressLocation{
+			Address: common.Address(sequelAddress),
+			Name:    common.AddressLocationPrefix,
+		},
+		"DigitalArt.Metadata",
+		fields,
+		nil,
+	))
+}
+
+func TestDigitalArtMetadata_RoundTrip(t *testing.T) {
+	metadata := sampleMetadata()
+
+	val := DigitalArtMetadataToCadence(metadata, sequelAddress)
+
+	res, layout, err := DigitalArtMetadataFromCadenceWithLayout(val)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, res)
+	assert.Equal(t, LatestMetadataLayout, layout)
+
+	res, err = DigitalArtMetadataFromCadence(cadence.NewOptional(val))
+	require.NoError(t, err)
+	assert.Equal(t, metadata, res)
+
+	res, err = DigitalArtMetadataFromCadence(cadence.NewOptional(nil))
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	_, err = DigitalArtMetadataToCadenceWithLayout(metadata, sequelAddress, LatestMetadataLayout+1)
+	assert.Error(t, err)
+}
+
+func TestDigitalArtMetadataFromCadence_UnknownFields(t *testing.T) {
+	val := modifiedMetadataValue(
+		func(fields []cadence.Field, values []cadence.Value) ([]cadence.Field, []cadence.Value) {
+			return append(fields, cadence.Field{Identifier: "royaltyOverride", Type: cadence.UFix64Type}),
+				append(values, cadence.UFix64(5_000_000))
+		})
+
+	res, err := DigitalArtMetadataFromCadence(val)
+	require.NoError(t, err)
+	assert.Equal(t, sampleMetadata(), res)
+}
+
+func TestDigitalArtMetadataFromCadence_Errors(t *testing.T) {
+	val := modifiedMetadataValue(
+		func(fields []cadence.Field, values []cadence.Value) ([]cadence.Field, []cadence.Value) {
+			// drop "name" field and change the type of "edition"
+			values[7] = cadence.String("2")
+			fields[7].Type = cadence.StringType
+			return fields[1:], values[1:]
+		})
+
+	_, err := DigitalArtMetadataFromCadence(val)
+	require.Error(t, err)
+
+	var errs MetadataErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, MetadataErrors{
+		{Field: "name", Message: "missing"},
+		{Field: "edition", Message: "expected UInt64, got cadence.String"},
+	}, errs)
+	assert.Equal(t, "bad Metadata value: name: missing; edition: expected UInt64, got cadence.String", err.Error())
+
+	// wrong types
+
+	for _, bad := range []cadence.Value{
+		cadence.String("metadata"),
+		cadence.NewStruct(nil),
+		cadence.NewStruct(nil).WithType(cadence.NewStructType(nil, "DigitalArt.Other", nil, nil)),
+	} {
+		_, err = DigitalArtMetadataFromCadence(bad)
+		assert.ErrorAs(t, err, &errs)
+	}
+
+	// values don't match the type
+
+	malformed := cadence.NewStruct([]cadence.Value{cadence.String("a"), cadence.String("b")}).
+		WithType(cadence.NewStructType(nil, "DigitalArt.Metadata", []cadence.Field{
+			{Identifier: "name", Type: cadence.StringType},
+		}, nil))
+
+	_, err = DigitalArtMetadataFromCadence(malformed)
+	assert.ErrorAs(t, err, &errs)
+}
@@ -0,0 +1,182 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+)
+
+// Offer mirrors SequelOffers.OfferDetails structure, as returned by "offer_get_offers" script.
+type Offer struct {
+	OfferID      uint64
+	BuyerAddress flow.Address
+	NFTType      string
+	// NFTID is set if the offer is made for a specific token.
+	NFTID *uint64
+	// Asset is set if the offer is made for any edition of the given asset.
+	Asset            *string
+	PaymentVaultType string
+	Price            evergreen.UFix64
+	// Expiry is the time after which the offer can't be accepted, if set.
+	Expiry *time.Time
+}
+
+// OfferFromCadence decodes SequelOffers.OfferDetails value.
+func OfferFromCadence(val cadence.Value) (*Offer, error) {
+	valStruct, ok := val.(cadence.Struct)
+	if !ok || valStruct.StructType == nil || valStruct.StructType.QualifiedIdentifier != "SequelOffers.OfferDetails" {
+		return nil, errors.New("not a SequelOffers.OfferDetails value")
+	}
+
+	fields, err := structFields(valStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Offer
+	if res.OfferID, err = uint64Field(fields, "offerID"); err != nil {
+		return nil, err
+	}
+	if res.BuyerAddress, err = addressField(fields, "buyerAddress"); err != nil {
+		return nil, err
+	}
+	if res.NFTType, err = typeField(fields, "nftType"); err != nil {
+		return nil, err
+	}
+	if res.NFTID, err = optionalUInt64Field(fields, "nftID"); err != nil {
+		return nil, err
+	}
+	if res.Asset, err = optionalStringField(fields, "asset"); err != nil {
+		return nil, err
+	}
+	if res.PaymentVaultType, err = typeField(fields, "paymentVaultType"); err != nil {
+		return nil, err
+	}
+	if res.Price, err = ufix64Field(fields, "price"); err != nil {
+		return nil, err
+	}
+	expiry, err := optionalUFix64Field(fields, "expiry")
+	if err != nil {
+		return nil, err
+	}
+	if expiry != nil {
+		t := UFix64ToTime(*expiry)
+		res.Expiry = &t
+	}
+
+	return &res, nil
+}
+
+// MakeOffer escrows the price, in the given fungible token, for the DigitalArt NFT
+// with the given ID. If expiry is not nil, the offer can't be accepted after this time.
+// It returns the offer ID.
+func (c *Client) MakeOffer(ctx context.Context, buyer string, tokenID uint64, price string, token FungibleTokenContract, expiry *time.Time) (uint64, error) {
+	return c.makeOffer(ctx, buyer, cadence.NewOptional(cadence.UInt64(tokenID)), cadence.NewOptional(nil), price, token, expiry)
+}
+
+// MakeAssetOffer escrows the price, in the given fungible token, for any edition
+// of the given asset. If expiry is not nil, the offer can't be accepted after this time.
+// It returns the offer ID.
+func (c *Client) MakeAssetOffer(ctx context.Context, buyer string, assetID string, price string, token FungibleTokenContract, expiry *time.Time) (uint64, error) {
+	return c.makeOffer(ctx, buyer, cadence.NewOptional(nil), cadence.NewOptional(cadence.String(assetID)), price, token, expiry)
+}
+
+func (c *Client) makeOffer(ctx context.Context, buyer string, tokenID, assetID cadence.Optional, price string, token FungibleTokenContract, expiry *time.Time) (uint64, error) {
+	res, err := c.se.NewTransaction("offer_make").
+		SignProposeAndPayAs(buyer).
+		Argument(tokenID).
+		Argument(assetID).
+		UFix64Argument(price).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		Argument(optionalTime(expiry)).
+		RunE(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	made, err := c.decoder.OfferMadeEvents(res.Events)
+	if err != nil {
+		return 0, err
+	}
+	if len(made) == 0 {
+		return 0, errors.New("OfferMade event not found")
+	}
+
+	return made[0].OfferID, nil
+}
+
+// AcceptOffer sells the seller's DigitalArt NFT to the buyer. The escrowed funds
+// are distributed according to the token's Evergreen profile; the seller's share
+// is paid in the given fungible token. The offer's price and currency must match
+// the given price and token, so that the seller gets what they expect.
+func (c *Client) AcceptOffer(ctx context.Context, seller string, buyer flow.Address, offerID, tokenID uint64, price string, token FungibleTokenContract) (*OfferAcceptedEvent, error) {
+	res, err := c.se.NewTransaction("offer_accept").
+		SignProposeAndPayAs(seller).
+		Argument(cadence.NewAddress(buyer)).
+		UInt64Argument(offerID).
+		UInt64Argument(tokenID).
+		UFix64Argument(price).
+		Argument(cadence.NewAddress(token.Address)).
+		StringArgument(token.Name).
+		RunE(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted, err := c.decoder.OfferAcceptedEvents(res.Events)
+	if err != nil {
+		return nil, err
+	}
+	if len(accepted) == 0 {
+		return nil, errors.New("OfferAccepted event not found")
+	}
+
+	return accepted[0], nil
+}
+
+// CancelOffer returns the escrowed funds to the buyer.
+func (c *Client) CancelOffer(ctx context.Context, buyer string, offerID uint64) error {
+	_, err := c.se.NewTransaction("offer_cancel").
+		SignProposeAndPayAs(buyer).
+		UInt64Argument(offerID).
+		RunE(ctx)
+
+	return err
+}
+
+// GetOffers returns all open offers made by the buyer.
+func (c *Client) GetOffers(ctx context.Context, buyer flow.Address) ([]*Offer, error) {
+	val, err := c.se.NewScript("offer_get_offers").
+		Argument(cadence.NewAddress(buyer)).
+		RunReturns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := val.(cadence.Array)
+	if !ok {
+		return nil, errors.New("bad offer_get_offers result")
+	}
+
+	res := make([]*Offer, len(arr.Values))
+	for i, v := range arr.Values {
+		if res[i], err = OfferFromCadence(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func typeField(fields map[string]cadence.Value, name string) (string, error) {
+	val, ok := fields[name].(cadence.TypeValue)
+	if !ok || val.StaticType == nil {
+		return "", errors.New("bad " + name + " value")
+	}
+	return val.StaticType.ID(), nil
+}
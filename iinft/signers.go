@@ -0,0 +1,252 @@
+package iinft
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/piprate/splash"
+)
+
+const (
+	// KeyTypeRemote is the key type of RemoteKey.
+	KeyTypeRemote config.KeyType = "remote"
+
+	// DefaultRemoteSignerTimeout is the timeout of requests to the remote signer,
+	// if NewRemoteKey isn't given an HTTP client.
+	DefaultRemoteSignerTimeout = 30 * time.Second
+)
+
+var ErrNoSignerURL = errors.New("remote signer URL not specified")
+
+type (
+	// KeyOptions describes an account key used to sign transactions.
+	KeyOptions struct {
+		// Index is the index of the key in the account.
+		Index uint32
+		// SigAlgo is the signature algorithm. Defaults to ECDSA_P256.
+		SigAlgo crypto.SignatureAlgorithm
+		// HashAlgo is the hash algorithm. Defaults to SHA3_256.
+		HashAlgo crypto.HashAlgorithm
+	}
+
+	// RemoteKey is an account key that signs transactions with a remote HTTP signer,
+	// i.e. a service in front of a KMS. The private key never leaves the signer.
+	//
+	// The signer receives POST requests with {"message": "<hex>"} body, where the message
+	// is the domain-tagged transaction payload or envelope, and responds with
+	// {"signature": "<hex>"}. The signer is responsible for hashing the message with
+	// the key's hash algorithm. See NewRemoteSignerHandler for a local implementation.
+	RemoteKey struct {
+		url        string
+		publicKey  crypto.PublicKey
+		opts       KeyOptions
+		httpClient *http.Client
+	}
+
+	remoteSigner struct {
+		key *RemoteKey
+		ctx context.Context
+	}
+
+	signRequest struct {
+		Message string `json:"message"`
+	}
+
+	signResponse struct {
+		Signature string `json:"signature"`
+	}
+)
+
+var _ accounts.Key = &RemoteKey{}
+
+func (o KeyOptions) sigAlgo() crypto.SignatureAlgorithm {
+	if o.SigAlgo == crypto.UnknownSignatureAlgorithm {
+		return crypto.ECDSA_P256
+	}
+	return o.SigAlgo
+}
+
+func (o KeyOptions) hashAlgo() crypto.HashAlgorithm {
+	if o.HashAlgo == crypto.UnknownHashAlgorithm {
+		return crypto.SHA3_256
+	}
+	return o.HashAlgo
+}
+
+// NewInMemoryKey creates an account key from the given hex-encoded private key.
+func NewInMemoryKey(privateKeyHex string, opts KeyOptions) (accounts.Key, error) {
+	privateKey, err := crypto.DecodePrivateKeyHex(opts.sigAlgo(), strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts.NewHexKeyFromPrivateKey(opts.Index, opts.hashAlgo(), privateKey), nil
+}
+
+// NewFileKey creates an account key from a file that contains a hex-encoded private key.
+func NewFileKey(path string, opts KeyOptions) (accounts.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInMemoryKey(strings.TrimSpace(string(data)), opts)
+}
+
+// NewRemoteKey creates an account key that signs transactions with the remote signer
+// at the given URL. publicKey is the key registered in the account. If httpClient is nil,
+// an HTTP client with DefaultRemoteSignerTimeout is used, so that an unresponsive signer
+// doesn't block transactions indefinitely.
+func NewRemoteKey(url string, publicKey crypto.PublicKey, opts KeyOptions, httpClient *http.Client) *RemoteKey {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultRemoteSignerTimeout}
+	}
+
+	return &RemoteKey{
+		url:        url,
+		publicKey:  publicKey,
+		opts:       opts,
+		httpClient: httpClient,
+	}
+}
+
+func (k *RemoteKey) Type() config.KeyType {
+	return KeyTypeRemote
+}
+
+func (k *RemoteKey) Index() uint32 {
+	return k.opts.Index
+}
+
+func (k *RemoteKey) SigAlgo() crypto.SignatureAlgorithm {
+	return k.opts.sigAlgo()
+}
+
+func (k *RemoteKey) HashAlgo() crypto.HashAlgorithm {
+	return k.opts.hashAlgo()
+}
+
+func (k *RemoteKey) Signer(ctx context.Context) (crypto.Signer, error) {
+	return &remoteSigner{key: k, ctx: ctx}, nil
+}
+
+func (k *RemoteKey) ToConfig() config.AccountKey {
+	return config.AccountKey{
+		Type:     KeyTypeRemote,
+		Index:    k.opts.Index,
+		SigAlgo:  k.opts.sigAlgo(),
+		HashAlgo: k.opts.hashAlgo(),
+		Location: k.url,
+	}
+}
+
+func (k *RemoteKey) Validate() error {
+	if k.url == "" {
+		return ErrNoSignerURL
+	}
+	if k.publicKey == nil {
+		return errors.New("remote signer public key not specified")
+	}
+	return nil
+}
+
+func (k *RemoteKey) PrivateKey() (*crypto.PrivateKey, error) {
+	return nil, errors.New("private key not accessible")
+}
+
+func (s *remoteSigner) Sign(message []byte) ([]byte, error) {
+	body, err := json.Marshal(&signRequest{Message: hex.EncodeToString(message)})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.key.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.key.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("remote signer returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var res signResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(res.Signature)
+}
+
+func (s *remoteSigner) PublicKey() crypto.PublicKey {
+	return s.key.publicKey
+}
+
+// NewRemoteSignerHandler returns an HTTP handler that implements the remote signer protocol
+// (see RemoteKey) with the given signer. It's a local stand-in for a KMS-backed signer
+// in tests and development environments.
+func NewRemoteSignerHandler(signer crypto.Signer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req signRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		message, err := hex.DecodeString(req.Message)
+		if err != nil {
+			http.Error(w, "bad message", http.StatusBadRequest)
+			return
+		}
+
+		sig, err := signer.Sign(message)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&signResponse{Signature: hex.EncodeToString(sig)})
+	})
+}
+
+// RegisterSigner adds the account with the given key to the connector, so that it can be
+// used in SignAndProposeAs, PayAs and other signer settings of template engine transactions.
+// The name is prefixed with the network name, if the connector expects it (see
+// splash.Connector.Account).
+func RegisterSigner(client *splash.Connector, name string, address flow.Address, key accounts.Key) {
+	if client.PrependNetworkToAccountNames {
+		name = fmt.Sprintf("%s-%s", client.Services.Network().Name, name)
+	}
+
+	client.State.Accounts().AddOrUpdate(&accounts.Account{
+		Name:    name,
+		Address: address,
+		Key:     key,
+	})
+}
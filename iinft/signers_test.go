@@ -0,0 +1,82 @@
+package iinft_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	. "github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInMemoryKey(t *testing.T) {
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_secp256k1, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	opts := KeyOptions{Index: 3, SigAlgo: crypto.ECDSA_secp256k1, HashAlgo: crypto.SHA2_256}
+	key, err := NewInMemoryKey(privateKey.String(), opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(3), key.Index())
+	assert.Equal(t, crypto.ECDSA_secp256k1, key.SigAlgo())
+	assert.Equal(t, crypto.SHA2_256, key.HashAlgo())
+
+	path := filepath.Join(t.TempDir(), "key.pkey")
+	require.NoError(t, os.WriteFile(path, []byte(privateKey.String()+"\n"), 0o600))
+
+	fileKey, err := NewFileKey(path, opts)
+	require.NoError(t, err)
+	pk, err := fileKey.PrivateKey()
+	require.NoError(t, err)
+	assert.True(t, privateKey.Equals(*pk))
+
+	_, err = NewInMemoryKey("not a key", opts)
+	require.Error(t, err)
+}
+
+func TestRemoteKey(t *testing.T) {
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_secp256k1, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	localSigner, err := crypto.NewInMemorySigner(privateKey, crypto.SHA3_256)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(NewRemoteSignerHandler(localSigner))
+	defer srv.Close()
+
+	key := NewRemoteKey(srv.URL, privateKey.PublicKey(), KeyOptions{SigAlgo: crypto.ECDSA_secp256k1}, nil)
+	require.NoError(t, key.Validate())
+	assert.Equal(t, KeyTypeRemote, key.Type())
+	assert.Equal(t, crypto.SHA3_256, key.HashAlgo())
+
+	signer, err := key.Signer(context.Background())
+	require.NoError(t, err)
+
+	message := []byte("transaction envelope")
+	sig, err := signer.Sign(message)
+	require.NoError(t, err)
+
+	hasher, err := crypto.NewHasher(crypto.SHA3_256)
+	require.NoError(t, err)
+	valid, err := privateKey.PublicKey().Verify(sig, message, hasher)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "key disabled", http.StatusForbidden)
+	}))
+	defer failing.Close()
+
+	signer, err = NewRemoteKey(failing.URL, privateKey.PublicKey(), KeyOptions{}, nil).Signer(context.Background())
+	require.NoError(t, err)
+	_, err = signer.Sign(message)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key disabled")
+
+	require.ErrorIs(t, NewRemoteKey("", privateKey.PublicKey(), KeyOptions{}, nil).Validate(), ErrNoSignerURL)
+}
@@ -0,0 +1,353 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/storage/remote"
+	"github.com/onflow/flow-emulator/storage/sqlite"
+	"github.com/onflow/flow-go-sdk"
+	flowgo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flowkit/v2"
+	"github.com/piprate/splash"
+	"github.com/rs/zerolog"
+)
+
+// DryRunMargin is the share of the computation limit EstimateChunkSize leaves unused,
+// because computation depends on the chain state and can grow between the dry run
+// and the actual transaction.
+const DryRunMargin = 0.1
+
+var (
+	ErrDryRunNotSupported = errors.New("dry run requires an emulator connector (see NewForkConnector)")
+	ErrNoComputationUsage = errors.New("computation usage not reported: transaction fees must be enabled in the emulator")
+)
+
+type (
+	// DryRunResult describes the outcome of a transaction executed by DryRun.
+	DryRunResult struct {
+		// ComputationUsed is the computation used by the transaction. It's reported
+		// by FlowFees.FeesDeducted event, so it's zero if transaction fees are disabled.
+		ComputationUsed uint64
+		// ComputationLimit is the computation (gas) limit of the transaction.
+		ComputationLimit uint64
+		// Events lists the events that the transaction would emit.
+		Events []flow.Event
+		// BalanceChanges lists net fungible token balance changes, including transaction fees,
+		// sorted by account address and vault type.
+		BalanceChanges []*BalanceChange
+		// Error is the transaction error, if the transaction would fail.
+		Error error
+	}
+
+	// BalanceChange is a net change of the account's balance in the given fungible token.
+	BalanceChange struct {
+		Address flow.Address
+		// VaultType is the type ID of the token's vault, i.e. A.0ae53cb6e3f42a79.FlowToken.Vault
+		VaultType string
+		Delta     cadence.Fix64
+	}
+)
+
+// DryRun executes the transaction on the connector's in-memory emulator and rolls back
+// the emulator's state, so that no changes made by the transaction are kept. It returns
+// ErrDryRunNotSupported for connectors to other networks. To simulate transactions
+// for a live network, use a connector created by NewForkConnector.
+//
+// The transaction must be proposed and paid for by its main signer (see
+// splash.FlowTransactionBuilder.SignProposeAndPayAs). Other transactions must not run
+// on the same emulator during the dry run, because they would be rolled back, too.
+func DryRun(ctx context.Context, tb splash.FlowTransactionBuilder) (res *DryRunResult, err error) {
+	gw, ok := tb.Connector.Services.Gateway().(*splash.EmulatorGateway)
+	if !ok {
+		return nil, ErrDryRunNotSupported
+	}
+
+	if tb.Payer == nil || tb.Proposer == nil || tb.Proposer.Name != tb.Payer.Name {
+		return nil, errors.New("dry run transactions must be proposed and paid for by the same account")
+	}
+
+	block, err := tb.Connector.Services.GetBlock(ctx, flowkit.LatestBlockQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// the transaction may be committed even if sending it reports an error
+	defer func() {
+		if rollbackErr := gw.RollbackToBlockHeight(block.Height); rollbackErr != nil {
+			res = nil
+			err = errors.Join(err, fmt.Errorf("failed to roll back the dry run: %w", rollbackErr))
+		}
+	}()
+
+	tx, err := buildSignedTransaction(ctx, tb, tb.Payer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, txRes, err := tb.Connector.Services.SendSignedTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	computationUsed := txRes.ComputationUsage
+	if computationUsed == 0 {
+		computationUsed = computationFromFees(txRes.Events)
+	}
+
+	return &DryRunResult{
+		ComputationUsed:  computationUsed,
+		ComputationLimit: tb.GasLimit,
+		Events:           txRes.Events,
+		BalanceChanges:   balanceChanges(txRes.Events),
+		Error:            txRes.Error,
+	}, nil
+}
+
+// NewForkConnector creates a connector to an in-memory emulator that forks the network
+// in cfg at the given block height, or at the latest sealed block if height is zero.
+// The emulator reads the network's state from cfg.Host on demand and keeps its own
+// changes in memory, so that DryRun can simulate transactions for a live network.
+// Signatures and sequence numbers aren't checked, because the emulator doesn't have
+// the network's keys.
+func NewForkConnector(ctx context.Context, cfg *Config, height uint64) (*splash.Connector, error) {
+	if cfg.Host == "" {
+		return nil, ErrNoHost
+	}
+
+	state, err := cfg.State()
+	if err != nil {
+		return nil, err
+	}
+
+	networkDef, err := state.Networks().ByName(cfg.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcClient, err := dialGrpcClient(cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := grpcClient.GetNetworkParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	baseStore, err := sqlite.New(sqlite.InMemory)
+	if err != nil {
+		return nil, err
+	}
+
+	nopLogger := zerolog.Nop()
+	store, err := remote.New(baseStore, &nopLogger, remote.WithForkHost(cfg.Host), remote.WithForkHeight(height))
+	if err != nil {
+		return nil, err
+	}
+
+	gw := splash.NewEmulatorGatewayWithOpts(nil, splash.WithEmulatorOptions(
+		emulator.WithStore(store),
+		emulator.WithChainID(flowgo.ChainID(params.ChainID)),
+		emulator.WithTransactionFeesEnabled(true),
+		emulator.WithTransactionValidationEnabled(false),
+	))
+
+	// commit a reference block for new transactions, as the emulator server does when forking
+	if _, _, err = gw.ExecuteAndCommitBlock(nil); err != nil {
+		return nil, err
+	}
+
+	logger := splash.NewZeroLogger()
+
+	return &splash.Connector{
+		State:                        state,
+		Services:                     flowkit.NewFlowkit(state, *networkDef, gw, logger),
+		GRPCClient:                   grpcClient,
+		Logger:                       logger,
+		PrependNetworkToAccountNames: true,
+		Network:                      cfg.Network,
+	}, nil
+}
+
+// DryRunMintOnDemand simulates Client.MintOnDemand with DryRun.
+func (c *Client) DryRunMintOnDemand(ctx context.Context, buyer string, assetID string, numEditions uint64, unitPrice string,
+	token FungibleTokenContract, modID uint64, params MintOnDemandParameters) (*DryRunResult, error) {
+	tb, err := c.mintOnDemandTransaction(buyer, assetID, numEditions, unitPrice, token, modID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return DryRun(ctx, tb.SignProposeAndPayAs(c.adminAccount))
+}
+
+// DryRunMintBatch simulates the transaction that Client.MintBatch submits for a single chunk of items.
+func (c *Client) DryRunMintBatch(ctx context.Context, items []*BatchMintItem) (*DryRunResult, error) {
+	return DryRun(ctx, c.mintBatchTransaction(items).SignProposeAndPayAs(c.adminAccount))
+}
+
+// EstimateChunkSize estimates how many items fit into a single transaction with the given
+// computation limit. build returns the transaction for n items. The transactions for one and
+// two items are simulated with DryRun, and the computation is extrapolated linearly,
+// leaving DryRunMargin of the limit unused. If limit is zero, the transaction's limit is used.
+// The result is at least one.
+func EstimateChunkSize(ctx context.Context, limit uint64, build func(n int) (splash.FlowTransactionBuilder, error)) (int, error) {
+	var used [2]uint64
+	for i := range used {
+		tb, err := build(i + 1)
+		if err != nil {
+			return 0, err
+		}
+		if limit == 0 {
+			limit = tb.GasLimit
+		}
+
+		res, err := DryRun(ctx, tb.Gas(limit))
+		if err != nil {
+			return 0, err
+		}
+		if res.Error != nil {
+			return 0, res.Error
+		}
+		if res.ComputationUsed == 0 {
+			return 0, ErrNoComputationUsage
+		}
+		used[i] = res.ComputationUsed
+	}
+
+	perItem := max(used[1]-min(used[0], used[1]), 1)
+	overhead := used[0] - min(perItem, used[0])
+
+	budget := uint64(float64(limit) * (1 - DryRunMargin))
+	if budget <= overhead+perItem {
+		return 1, nil
+	}
+
+	return int((budget - overhead) / perItem), nil
+}
+
+// EstimateMintOnDemandEditions estimates how many editions of the master can be minted on demand
+// in a single transaction with the given computation limit (see EstimateChunkSize).
+// The buyer must be able to pay for two editions.
+func (c *Client) EstimateMintOnDemandEditions(ctx context.Context, buyer string, assetID string, unitPrice string,
+	token FungibleTokenContract, params MintOnDemandParameters, limit uint64) (int, error) {
+	return EstimateChunkSize(ctx, limit, func(n int) (splash.FlowTransactionBuilder, error) {
+		tb, err := c.mintOnDemandTransaction(buyer, assetID, uint64(n), unitPrice, token, 0, params)
+		if err != nil {
+			return splash.FlowTransactionBuilder{}, err
+		}
+		return tb.SignProposeAndPayAs(c.adminAccount), nil
+	})
+}
+
+// EstimateMintBatchChunkSize estimates BatchMintOptions.ChunkSize for minting editions
+// of the item's master with MintBatch (see EstimateChunkSize). The master must have
+// at least two available editions.
+func (c *Client) EstimateMintBatchChunkSize(ctx context.Context, item *BatchMintItem, limit uint64) (int, error) {
+	return EstimateChunkSize(ctx, limit, func(n int) (splash.FlowTransactionBuilder, error) {
+		items := make([]*BatchMintItem, n)
+		for i := range items {
+			items[i] = item
+		}
+		return c.mintBatchTransaction(items).SignProposeAndPayAs(c.adminAccount), nil
+	})
+}
+
+// EstimateListBatchChunkSize estimates BatchListOptions.ChunkSize for listing the seller's tokens
+// with ListBatch (see EstimateChunkSize). The item's token is listed repeatedly in the simulated
+// transactions, so it must be in the seller's collection.
+func (c *Client) EstimateListBatchChunkSize(ctx context.Context, seller string, item *BatchListItem, limit uint64) (int, error) {
+	return EstimateChunkSize(ctx, limit, func(n int) (splash.FlowTransactionBuilder, error) {
+		items := make([]*BatchListItem, n)
+		for i := range items {
+			items[i] = item
+		}
+		return c.listBatchTransaction(seller, items)
+	})
+}
+
+// computationFromFees returns the computation used by the transaction, as reported
+// by FlowFees.FeesDeducted event. The event's executionEffort field is the computation
+// used, encoded as a raw UFix64 value.
+func computationFromFees(events []flow.Event) uint64 {
+	for _, ev := range events {
+		if !strings.HasSuffix(ev.Type, ".FlowFees.FeesDeducted") {
+			continue
+		}
+		if effort, ok := ev.Value.FieldsMappedByName()["executionEffort"].(cadence.UFix64); ok {
+			return uint64(effort)
+		}
+	}
+
+	return 0
+}
+
+// balanceChanges sums up FungibleToken.Withdrawn and FungibleToken.Deposited events
+// by account and vault type. Withdrawals from and deposits to vaults that aren't stored
+// in accounts are ignored.
+func balanceChanges(events []flow.Event) []*BalanceChange {
+	type key struct {
+		address   flow.Address
+		vaultType string
+	}
+
+	deltas := make(map[key]int64)
+	for _, ev := range events {
+		var accountField string
+		var sign int64
+		switch {
+		case strings.HasSuffix(ev.Type, ".FungibleToken.Withdrawn"):
+			accountField, sign = "from", -1
+		case strings.HasSuffix(ev.Type, ".FungibleToken.Deposited"):
+			accountField, sign = "to", 1
+		default:
+			continue
+		}
+
+		fields := ev.Value.FieldsMappedByName()
+		opt, ok := fields[accountField].(cadence.Optional)
+		if !ok || opt.Value == nil {
+			continue
+		}
+		address, ok := opt.Value.(cadence.Address)
+		if !ok {
+			continue
+		}
+		vaultType, ok := fields["type"].(cadence.String)
+		if !ok {
+			continue
+		}
+		amount, ok := fields["amount"].(cadence.UFix64)
+		if !ok {
+			continue
+		}
+
+		deltas[key{flow.Address(address), string(vaultType)}] += sign * int64(amount)
+	}
+
+	res := make([]*BalanceChange, 0, len(deltas))
+	for k, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		res = append(res, &BalanceChange{
+			Address:   k.address,
+			VaultType: k.vaultType,
+			Delta:     cadence.Fix64(delta),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Address != res[j].Address {
+			return res[i].Address.Hex() < res[j].Address.Hex()
+		}
+		return res[i].VaultType < res[j].VaultType
+	})
+
+	return res
+}
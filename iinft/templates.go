@@ -17,8 +17,34 @@ var (
 		"Art", "Content",
 		"Evergreen", "DigitalArt", "SequelMarketplace",
 	}
+
+	// optionalWellKnownContracts are used by some templates, but may not be deployed on every network.
+	optionalWellKnownContracts = []string{
+		"SequelOffers", "SequelAuctions", "ViewResolver", "ExampleToken", "USDCFlow",
+	}
 )
 
 func NewTemplateEngine(client *splash.Connector) (*splash.TemplateEngine, error) {
 	return splash.NewTemplateEngine(client, templateFS, []string{}, requiredWellKnownContracts, "templates/transactions/*.cdc", "templates/scripts/*.cdc", "templates/scripts/**/*.cdc")
 }
+
+// NewTemplateEngineWithConfig creates a new template engine for the network described
+// by the given configuration. See NewConnector.
+func NewTemplateEngineWithConfig(cfg *Config) (*splash.TemplateEngine, error) {
+	client, err := NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTemplateEngine(client)
+}
+
+// GetMintOnDemandScript renders "digitalart_mint_on_demand" or "digitalart_mint_on_demand_flow"
+// transaction template. The parameters are validated first, so that a bad Evergreen profile
+// is reported before the transaction is sent.
+func GetMintOnDemandScript(se *splash.TemplateEngine, templateID string, params MintOnDemandParameters) (string, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+	return se.GetCustomScript(templateID, params), nil
+}
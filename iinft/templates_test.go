@@ -6,7 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	. "github.com/piprate/sequel-flow-contracts/iinft"
 	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
@@ -44,18 +43,7 @@ func TestNewEngine_emulatorWithFees(t *testing.T) {
 	_, err = client.DoNotPrependNetworkToAccountNames().CreateAccountsE(ctx, "emulator-account")
 	require.NoError(t, err)
 
-	te, err := NewTemplateEngine(client)
-	require.NoError(t, err)
-
-	adminAcct := client.Account("emulator-sequel-admin")
-	_ = te.NewTransaction("account_fund_flow").
-		Argument(cadence.NewAddress(adminAcct.Address)).
-		UFix64Argument("1000.0").
-		SignProposeAndPayAsService().
-		Test(t).
-		AssertSuccess()
-
-	err = client.InitializeContractsE(ctx)
+	err = InitializeContractsE(ctx, client, "emulator-sequel-admin", "1000.0")
 	require.NoError(t, err)
 }
 
@@ -150,8 +138,8 @@ func TestEngine_GetCustomScript_MOD_Flow(t *testing.T) {
 			Roles: []*evergreen.Role{
 				{
 					ID:                        evergreen.RoleArtist,
-					InitialSaleCommission:     0.8,
-					SecondaryMarketCommission: 0.2,
+					InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+					SecondaryMarketCommission: evergreen.MustParseUFix64("0.2"),
 					Address:                   flow.HexToAddress("0xf669cb8d41ce0c74"),
 				},
 			},
@@ -160,3 +148,55 @@ func TestEngine_GetCustomScript_MOD_Flow(t *testing.T) {
 
 	println(res)
 }
+
+func TestGetMintOnDemandScript(t *testing.T) {
+	client, err := NewNetworkConnectorEmbedded("mainnet")
+	require.NoError(t, err)
+
+	e, err := NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	metadata := &DigitalArtMetadata{
+		Name:       "Pure Art",
+		Artist:     "did:sequel:artist",
+		MaxEdition: 4,
+		Asset:      "did:sequel:asset-id",
+	}
+	profile := &evergreen.Profile{
+		ID: "did:sequel:evergreen1",
+		Roles: []*evergreen.Role{
+			{
+				ID:                        evergreen.RoleArtist,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.2"),
+				Address:                   flow.HexToAddress("0xf669cb8d41ce0c74"),
+			},
+		},
+	}
+
+	res, err := GetMintOnDemandScript(e, "digitalart_mint_on_demand", MintOnDemandParameters{
+		Metadata: metadata,
+		Profile:  profile,
+	})
+	require.NoError(t, err)
+	require.Contains(t, res, "sealMaster")
+
+	// the master is assumed to be sealed if no metadata provided
+
+	_, err = GetMintOnDemandScript(e, "digitalart_mint_on_demand", MintOnDemandParameters{})
+	require.NoError(t, err)
+
+	_, err = GetMintOnDemandScript(e, "digitalart_mint_on_demand", MintOnDemandParameters{
+		Metadata: metadata,
+	})
+	require.Error(t, err)
+
+	profile.Roles[0].ReceiverPath = "/public/flowTokenReceiver, address: 0x01"
+
+	_, err = GetMintOnDemandScript(e, "digitalart_mint_on_demand", MintOnDemandParameters{
+		Metadata: metadata,
+		Profile:  profile,
+	})
+	var errs evergreen.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+}
@@ -0,0 +1,486 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/common"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Auctions(t *testing.T) {
+	client, err := testscripts.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	artistAcct := client.Account(platformAccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName)
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	bidder1AcctName := user2AccountName
+	bidder1Acct := client.Account(bidder1AcctName)
+	testscripts.FundAccountWithFlow(t, se, bidder1Acct.Address, "1000.0")
+
+	bidder2AcctName := user3AccountName
+	bidder2Acct := client.Account(bidder2AcctName)
+	testscripts.FundAccountWithFlow(t, se, bidder2Acct.Address, "1000.0")
+
+	metadata := SampleMetadata(6)
+	require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+	nftIDs, err := c.MintEdition(ctx, metadata.Asset, 6, sellerAcct.Address)
+	require.NoError(t, err)
+	require.Len(t, nftIDs, 6)
+
+	t.Run("Should be able to run an English auction", func(t *testing.T) {
+		auctionID, err := c.CreateEnglishAuction(ctx, sellerAcctName, nftIDs[0], iinft.EnglishAuctionParameters{
+			StartPrice:      "10.0",
+			ReservePrice:    "20.0",
+			MinIncrement:    "5.0",
+			Duration:        6 * time.Second,
+			ExtensionWindow: 3 * time.Second,
+		}, flowToken)
+		require.NoError(t, err)
+
+		checkDigitalArtCollectionLen(t, se, sellerAcct.Address.String(), 5)
+
+		auction, err := c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		require.NotNil(t, auction)
+		assert.Equal(t, iinft.EnglishAuction, auction.Kind)
+		assert.Equal(t, nftIDs[0], auction.NFTID)
+		assert.Equal(t, metadata.Asset, auction.Asset)
+		assert.Equal(t, evergreen.MustParseUFix64("10.0"), auction.CurrentPrice)
+		assert.Equal(t, 6*time.Second, auction.EndTime.Sub(auction.StartTime))
+		assert.Nil(t, auction.HighestBid)
+
+		_, _, err = c.PlaceBid(ctx, bidder1AcctName, auctionID, "8.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Bid too low")
+
+		bid, settled, err := c.PlaceBid(ctx, bidder1AcctName, auctionID, "10.0", flowToken)
+		require.NoError(t, err)
+		assert.Nil(t, settled)
+		assert.Equal(t, bidder1Acct.Address, bid.BidderAddress)
+
+		_, _, err = c.PlaceBid(ctx, bidder2AcctName, auctionID, "12.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Bid too low")
+
+		bidder1Balance := testscripts.GetFlowBalance(t, se, bidder1Acct.Address)
+
+		_, _, err = c.PlaceBid(ctx, bidder2AcctName, auctionID, "25.0", flowToken)
+		require.NoError(t, err)
+
+		// the outbid bidder is refunded
+		assert.InDelta(t, bidder1Balance+10.0, testscripts.GetFlowBalance(t, se, bidder1Acct.Address), 0.00000001)
+
+		err = c.CancelAuction(ctx, sellerAcctName, auctionID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Auction has bids")
+
+		_, err = c.SettleAuction(ctx, adminAccountName, auctionID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Auction not ended")
+
+		auction, err = c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		require.NotNil(t, auction.HighestBid)
+		assert.Equal(t, evergreen.MustParseUFix64("25.0"), *auction.HighestBid)
+		assert.Equal(t, bidder2Acct.Address, *auction.HighestBidder)
+		assert.Equal(t, evergreen.MustParseUFix64("30.0"), auction.CurrentPrice)
+
+		// a bid within the extension window extends the auction
+
+		testscripts.AdvanceBlockTime(t, client, auction.EndTime.Add(-2*time.Second))
+
+		bid, _, err = c.PlaceBid(ctx, bidder1AcctName, auctionID, "30.0", flowToken)
+		require.NoError(t, err)
+		assert.True(t, bid.EndTime.After(auction.EndTime))
+
+		testscripts.AdvanceBlockTime(t, client, bid.EndTime.Add(time.Second))
+
+		_, _, err = c.PlaceBid(ctx, bidder2AcctName, auctionID, "50.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Auction ended")
+
+		artistBalance := testscripts.GetFlowBalance(t, se, artistAcct.Address)
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		// anyone may settle the auction
+		ev, err := c.SettleAuction(ctx, adminAccountName, auctionID)
+		require.NoError(t, err)
+
+		require.NotNil(t, ev.WinnerAddress)
+		assert.Equal(t, bidder1Acct.Address, *ev.WinnerAddress)
+		require.NotNil(t, ev.Price)
+		assert.Equal(t, evergreen.MustParseUFix64("30.0"), *ev.Price)
+		assert.Equal(t, []*evergreen.Payment{
+			{
+				Role:     evergreen.RoleArtist,
+				Receiver: artistAcct.Address,
+				Amount:   evergreen.MustParseUFix64("1.5"),
+				Rate:     evergreen.MustParseUFix64("0.05"),
+			},
+			{
+				Role:     evergreen.RoleOwner,
+				Receiver: sellerAcct.Address,
+				Amount:   evergreen.MustParseUFix64("28.5"),
+				Rate:     evergreen.MustParseUFix64("0.95"),
+			},
+		}, ev.Payments)
+
+		assert.InDelta(t, artistBalance+1.5, testscripts.GetFlowBalance(t, se, artistAcct.Address), 0.00000001)
+		assert.InDelta(t, sellerBalance+28.5, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.00000001)
+
+		checkTokenInDigitalArtCollection(t, se, bidder1Acct.Address.String(), nftIDs[0])
+
+		auction, err = c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		assert.Nil(t, auction)
+	})
+
+	t.Run("Should return the token if the reserve price isn't met", func(t *testing.T) {
+		auctionID, err := c.CreateEnglishAuction(ctx, sellerAcctName, nftIDs[1], iinft.EnglishAuctionParameters{
+			StartPrice:   "10.0",
+			ReservePrice: "100.0",
+			MinIncrement: "1.0",
+			Duration:     2 * time.Second,
+		}, flowToken)
+		require.NoError(t, err)
+
+		bidder2Balance := testscripts.GetFlowBalance(t, se, bidder2Acct.Address)
+
+		bid, _, err := c.PlaceBid(ctx, bidder2AcctName, auctionID, "50.0", flowToken)
+		require.NoError(t, err)
+
+		testscripts.AdvanceBlockTime(t, client, bid.EndTime.Add(time.Second))
+
+		ev, err := c.SettleAuction(ctx, adminAccountName, auctionID)
+		require.NoError(t, err)
+		assert.Nil(t, ev.WinnerAddress)
+		assert.Nil(t, ev.Price)
+		assert.Empty(t, ev.Payments)
+
+		assert.InDelta(t, bidder2Balance, testscripts.GetFlowBalance(t, se, bidder2Acct.Address), 0.001)
+
+		checkTokenInDigitalArtCollection(t, se, sellerAcct.Address.String(), nftIDs[1])
+	})
+
+	t.Run("Should be able to cancel an auction without bids", func(t *testing.T) {
+		auctionID, err := c.CreateEnglishAuction(ctx, sellerAcctName, nftIDs[1], iinft.EnglishAuctionParameters{
+			StartPrice:   "10.0",
+			ReservePrice: "10.0",
+			MinIncrement: "1.0",
+			Duration:     time.Hour,
+		}, flowToken)
+		require.NoError(t, err)
+
+		checkDigitalArtCollectionLen(t, se, sellerAcct.Address.String(), 4)
+
+		require.NoError(t, c.CancelAuction(ctx, sellerAcctName, auctionID))
+
+		checkTokenInDigitalArtCollection(t, se, sellerAcct.Address.String(), nftIDs[1])
+
+		auctions, err := c.GetAuctions(ctx, sellerAcct.Address)
+		require.NoError(t, err)
+		assert.Empty(t, auctions)
+	})
+
+	t.Run("Should be able to run a Dutch auction", func(t *testing.T) {
+		auctionID, err := c.CreateDutchAuction(ctx, sellerAcctName, nftIDs[2], iinft.DutchAuctionParameters{
+			StartPrice: "100.0",
+			FloorPrice: "50.0",
+			PriceDrop:  "10.0",
+			Duration:   time.Hour,
+		}, flowToken)
+		require.NoError(t, err)
+
+		auction, err := c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		require.NotNil(t, auction)
+		assert.Equal(t, iinft.DutchAuction, auction.Kind)
+		assert.Equal(t, evergreen.MustParseUFix64("50.0"), auction.ReservePrice)
+
+		// the price falls by 10.0 per second
+		testscripts.AdvanceBlockTime(t, client, auction.StartTime.Add(2*time.Second))
+
+		auction, err = c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		assert.Equal(t, evergreen.MustParseUFix64("80.0"), auction.CurrentPrice)
+
+		_, _, err = c.PlaceBid(ctx, bidder2AcctName, auctionID, "40.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Bid too low")
+
+		bidder2Balance := testscripts.GetFlowBalance(t, se, bidder2Acct.Address)
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		// the first bid that covers the current price wins, the excess is refunded
+		_, ev, err := c.PlaceBid(ctx, bidder2AcctName, auctionID, "100.0", flowToken)
+		require.NoError(t, err)
+		require.NotNil(t, ev)
+		require.NotNil(t, ev.WinnerAddress)
+		assert.Equal(t, bidder2Acct.Address, *ev.WinnerAddress)
+		require.NotNil(t, ev.Price)
+		assert.Equal(t, evergreen.MustParseUFix64("80.0"), *ev.Price)
+
+		assert.InDelta(t, bidder2Balance-80.0, testscripts.GetFlowBalance(t, se, bidder2Acct.Address), 0.001)
+		assert.InDelta(t, sellerBalance+76.0, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.00000001)
+
+		checkTokenInDigitalArtCollection(t, se, bidder2Acct.Address.String(), nftIDs[2])
+
+		auctions, err := c.GetAuctions(ctx, sellerAcct.Address)
+		require.NoError(t, err)
+		assert.Empty(t, auctions)
+	})
+
+	t.Run("Should not overflow when the Dutch auction price reaches the floor", func(t *testing.T) {
+		auctionID, err := c.CreateDutchAuction(ctx, sellerAcctName, nftIDs[3], iinft.DutchAuctionParameters{
+			StartPrice: "100.0",
+			FloorPrice: "50.0",
+			PriceDrop:  "100000000000.0",
+			Duration:   time.Hour,
+		}, flowToken)
+		require.NoError(t, err)
+
+		auction, err := c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+
+		// priceDrop * elapsed time exceeds the maximum UFix64 value
+		testscripts.AdvanceBlockTime(t, client, auction.StartTime.Add(10*time.Second))
+
+		auction, err = c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		assert.Equal(t, evergreen.MustParseUFix64("50.0"), auction.CurrentPrice)
+
+		require.NoError(t, c.CancelAuction(ctx, sellerAcctName, auctionID))
+	})
+
+	t.Run("Should settle after the seller destroys the auction house and unlinks the receiver", func(t *testing.T) {
+		auctionID, err := c.CreateEnglishAuction(ctx, sellerAcctName, nftIDs[5], iinft.EnglishAuctionParameters{
+			StartPrice:   "10.0",
+			ReservePrice: "10.0",
+			MinIncrement: "1.0",
+			Duration:     time.Hour,
+		}, flowToken)
+		require.NoError(t, err)
+
+		bid, _, err := c.PlaceBid(ctx, bidder1AcctName, auctionID, "20.0", flowToken)
+		require.NoError(t, err)
+
+		_ = client.Transaction(`
+		import SequelAuctions from 0x179b6b1cb6755e31
+
+		transaction {
+			prepare(acct: auth(LoadValue) &Account) {
+				destroy acct.storage.load<@SequelAuctions.AuctionHouse>(from: SequelAuctions.AuctionHouseStoragePath)!
+			}
+		}`).
+			SignProposeAndPayAs(sellerAcctName).
+			Test(t).
+			AssertSuccess()
+
+		revokeCapability(t, client, sellerAcctName, "flowTokenReceiver")
+
+		testscripts.AdvanceBlockTime(t, client, bid.EndTime.Add(time.Second))
+
+		artistBalance := testscripts.GetFlowBalance(t, se, artistAcct.Address)
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		ev, err := c.SettleAuction(ctx, adminAccountName, auctionID)
+		require.NoError(t, err)
+		require.NotNil(t, ev.WinnerAddress)
+		assert.Equal(t, bidder1Acct.Address, *ev.WinnerAddress)
+
+		checkTokenInDigitalArtCollection(t, se, bidder1Acct.Address.String(), nftIDs[5])
+
+		// the artist is paid, while the seller's proceeds are held
+		assert.InDelta(t, artistBalance+1.0, testscripts.GetFlowBalance(t, se, artistAcct.Address), 0.00000001)
+
+		unclaimed, err := c.GetUnclaimedAuctionBalance(ctx, sellerAcct.Address, flowToken)
+		require.NoError(t, err)
+		assert.Equal(t, evergreen.MustParseUFix64("19.0"), unclaimed)
+
+		require.NoError(t, c.ClaimAuctionItems(ctx, sellerAcctName, flowToken))
+
+		assert.InDelta(t, sellerBalance+19.0, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.001)
+
+		publishFlowTokenReceiver(t, client, sellerAcctName)
+	})
+
+	t.Run("Should let the highest bidder reclaim an unsettled bid", func(t *testing.T) {
+		auctionID, err := c.CreateEnglishAuction(ctx, sellerAcctName, nftIDs[3], iinft.EnglishAuctionParameters{
+			StartPrice:   "10.0",
+			ReservePrice: "10.0",
+			MinIncrement: "1.0",
+			Duration:     time.Hour,
+		}, flowToken)
+		require.NoError(t, err)
+
+		bidder1Balance := testscripts.GetFlowBalance(t, se, bidder1Acct.Address)
+
+		bid, _, err := c.PlaceBid(ctx, bidder1AcctName, auctionID, "15.0", flowToken)
+		require.NoError(t, err)
+
+		testscripts.AdvanceBlockTime(t, client, bid.EndTime.Add(time.Second))
+
+		err = c.ReclaimBid(ctx, bidder1AcctName, auctionID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Settlement period not over")
+
+		testscripts.AdvanceBlockTime(t, client, bid.EndTime.Add(7*24*time.Hour+time.Second))
+
+		require.NoError(t, c.ReclaimBid(ctx, bidder1AcctName, auctionID))
+
+		assert.InDelta(t, bidder1Balance, testscripts.GetFlowBalance(t, se, bidder1Acct.Address), 0.001)
+
+		checkTokenInDigitalArtCollection(t, se, sellerAcct.Address.String(), nftIDs[3])
+
+		auction, err := c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		assert.Nil(t, auction)
+	})
+
+	auctionID, err := c.CreateEnglishAuction(ctx, sellerAcctName, nftIDs[4], iinft.EnglishAuctionParameters{
+		StartPrice:   "10.0",
+		ReservePrice: "10.0",
+		MinIncrement: "1.0",
+		Duration:     time.Hour,
+	}, flowToken)
+	require.NoError(t, err)
+
+	t.Run("Should hold refunds if the outbid bidder's receiver is missing", func(t *testing.T) {
+		_, _, err := c.PlaceBid(ctx, bidder1AcctName, auctionID, "10.0", flowToken)
+		require.NoError(t, err)
+
+		revokeCapability(t, client, bidder1AcctName, "flowTokenReceiver")
+
+		bidder1Balance := testscripts.GetFlowBalance(t, se, bidder1Acct.Address)
+
+		_, _, err = c.PlaceBid(ctx, bidder2AcctName, auctionID, "20.0", flowToken)
+		require.NoError(t, err)
+
+		assert.InDelta(t, bidder1Balance, testscripts.GetFlowBalance(t, se, bidder1Acct.Address), 0.00000001)
+
+		unclaimed, err := c.GetUnclaimedAuctionBalance(ctx, bidder1Acct.Address, flowToken)
+		require.NoError(t, err)
+		assert.Equal(t, evergreen.MustParseUFix64("10.0"), unclaimed)
+
+		require.NoError(t, c.ClaimAuctionItems(ctx, bidder1AcctName, flowToken))
+
+		assert.InDelta(t, bidder1Balance+10.0, testscripts.GetFlowBalance(t, se, bidder1Acct.Address), 0.001)
+
+		unclaimed, err = c.GetUnclaimedAuctionBalance(ctx, bidder1Acct.Address, flowToken)
+		require.NoError(t, err)
+		assert.Zero(t, unclaimed)
+	})
+
+	t.Run("Should settle if the winner's receivers are missing", func(t *testing.T) {
+		auction, err := c.GetAuction(ctx, auctionID)
+		require.NoError(t, err)
+		require.NotNil(t, auction.HighestBidder)
+		require.Equal(t, bidder2Acct.Address, *auction.HighestBidder)
+
+		revokeCapability(t, client, bidder2AcctName, "flowTokenReceiver")
+		revokeCapability(t, client, bidder2AcctName, "sequelDigitalArtCollectionV2")
+
+		testscripts.AdvanceBlockTime(t, client, auction.EndTime.Add(time.Second))
+
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		ev, err := c.SettleAuction(ctx, adminAccountName, auctionID)
+		require.NoError(t, err)
+		require.NotNil(t, ev.WinnerAddress)
+		assert.Equal(t, bidder2Acct.Address, *ev.WinnerAddress)
+
+		// the seller is paid, while the token is held for the winner
+		assert.InDelta(t, sellerBalance+19.0, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.00000001)
+
+		unclaimedNFTs, err := c.GetUnclaimedAuctionNFTs(ctx, bidder2Acct.Address)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{nftIDs[4]}, unclaimedNFTs)
+
+		require.NoError(t, c.ClaimAuctionItems(ctx, bidder2AcctName, flowToken))
+
+		unclaimedNFTs, err = c.GetUnclaimedAuctionNFTs(ctx, bidder2Acct.Address)
+		require.NoError(t, err)
+		assert.Empty(t, unclaimedNFTs)
+
+		// the winner's collection isn't published, so the history is read from storage
+		history, err := client.Script(`
+		import Evergreen from 0x179b6b1cb6755e31
+		import DigitalArt from 0x179b6b1cb6755e31
+
+		access(all) fun main(owner: Address, tokenId: UInt64): [Address] {
+			let collection = getAuthAccount<auth(BorrowValue) &Account>(owner).storage.borrow<&DigitalArt.Collection>(from: DigitalArt.CollectionStoragePath)!
+			return Evergreen.getOwnerHistory(token: collection.borrowEvergreenToken(id: tokenId)!)
+		}
+		`).
+			Argument(cadence.NewAddress(bidder2Acct.Address)).
+			UInt64Argument(nftIDs[4]).
+			RunReturns(ctx)
+		require.NoError(t, err)
+		owners := history.(cadence.Array).Values
+		assert.Equal(t, cadence.NewAddress(bidder2Acct.Address), owners[len(owners)-1])
+	})
+}
+
+// revokeCapability deletes the controller of the capability published at /public/<identifier>
+// in the account, so that copies of the capability, including those held by contracts, become invalid.
+func revokeCapability(t *testing.T, client *splash.Connector, accountName, identifier string) {
+	t.Helper()
+
+	_ = client.Transaction(`
+transaction(path: PublicPath) {
+    prepare(acct: auth(GetStorageCapabilityController, UnpublishCapability) &Account) {
+        let capability = acct.capabilities.unpublish(path) ?? panic("Capability not found")
+        acct.capabilities.storage.getController(byCapabilityID: capability.id)!.delete()
+    }
+}`).
+		SignProposeAndPayAs(accountName).
+		Argument(cadence.Path{Domain: common.PathDomainPublic, Identifier: identifier}).
+		Test(t).
+		AssertSuccess()
+}
+
+// publishFlowTokenReceiver publishes a new capability to the account's Flow token vault
+// at /public/flowTokenReceiver.
+func publishFlowTokenReceiver(t *testing.T, client *splash.Connector, accountName string) {
+	t.Helper()
+
+	_ = client.Transaction(`
+import FungibleToken from 0xee82856bf20e2aa6
+
+transaction {
+    prepare(acct: auth(IssueStorageCapabilityController, PublishCapability) &Account) {
+        let capability = acct.capabilities.storage.issue<&{FungibleToken.Receiver}>(/storage/flowTokenVault)
+        acct.capabilities.publish(capability, at: /public/flowTokenReceiver)
+    }
+}`).
+		SignProposeAndPayAs(accountName).
+		Test(t).
+		AssertSuccess()
+}
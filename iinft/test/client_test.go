@@ -0,0 +1,653 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Lifecycle(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	platformAcct := client.Account(platformAccountName)
+
+	// set up seller account
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	testscripts.SetUpRoyaltyReceivers(t, se, sellerAcctName, sellerAcctName)
+
+	// set up buyer account
+
+	buyerAcctName := user2AccountName
+	buyerAcct := client.Account(buyerAcctName)
+
+	testscripts.FundAccountWithFlow(t, se, buyerAcct.Address, "1000.0")
+
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(buyerAcctName).Test(t).AssertSuccess()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	metadata := SampleMetadata(2)
+	profile := PrimaryOnlyEvergreenProfile(sellerAcct.Address, platformAcct.Address)
+
+	var nftIDs []uint64
+	var listingID uint64
+
+	t.Run("Should be able to seal a master", func(t *testing.T) {
+		err := c.SealMaster(ctx, metadata, profile)
+		require.NoError(t, err)
+
+		err = c.SealMaster(ctx, metadata, profile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master already sealed")
+	})
+
+	t.Run("Should be able to mint editions", func(t *testing.T) {
+		nftIDs, err = c.MintEdition(ctx, metadata.Asset, 2, sellerAcct.Address)
+		require.NoError(t, err)
+		require.Len(t, nftIDs, 2)
+
+		checkDigitalArtCollectionLen(t, se, sellerAcct.Address.String(), 2)
+	})
+
+	t.Run("Should be able to list a token", func(t *testing.T) {
+		link := "link"
+		listingID, err = c.ListToken(ctx, sellerAcctName, nftIDs[0], "200.0", flowToken, &link)
+		require.NoError(t, err)
+		assert.NotZero(t, listingID)
+	})
+
+	t.Run("Should be able to buy a token", func(t *testing.T) {
+		nftID, err := c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, listingID, flowToken, nil)
+		require.NoError(t, err)
+		assert.Equal(t, nftIDs[0], nftID)
+
+		checkTokenInDigitalArtCollection(t, se, buyerAcct.Address.String(), nftID)
+		checkDigitalArtCollectionLen(t, se, sellerAcct.Address.String(), 1)
+	})
+
+	t.Run("Should be able to withdraw a listing", func(t *testing.T) {
+		listingID, err := c.ListToken(ctx, sellerAcctName, nftIDs[1], "100.0", flowToken, nil)
+		require.NoError(t, err)
+
+		err = c.WithdrawListing(ctx, sellerAcctName, listingID)
+		require.NoError(t, err)
+
+		err = c.WithdrawListing(ctx, sellerAcctName, listingID)
+		require.Error(t, err)
+	})
+
+	t.Run("Should be able to transfer a token", func(t *testing.T) {
+		err := c.Transfer(ctx, sellerAcctName, nftIDs[1], buyerAcct.Address)
+		require.NoError(t, err)
+
+		checkDigitalArtCollectionLen(t, se, sellerAcct.Address.String(), 0)
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 2)
+	})
+
+	t.Run("Should be able to mint on demand", func(t *testing.T) {
+		onDemandMetadata := SampleMetadata(3)
+		onDemandMetadata.Asset = "did:sequel:asset-id-on-demand"
+
+		_, err := c.MintOnDemand(ctx, buyerAcctName, onDemandMetadata.Asset, 1, "100.0", flowToken, 0,
+			iinft.MintOnDemandParameters{Metadata: onDemandMetadata})
+		require.Error(t, err)
+
+		ids, err := c.MintOnDemand(ctx, buyerAcctName, onDemandMetadata.Asset, 2, "100.0", flowToken, 0,
+			iinft.MintOnDemandParameters{Metadata: onDemandMetadata, Profile: profile})
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+
+		// the master is sealed now, so metadata is not required
+
+		ids, err = c.MintOnDemand(ctx, buyerAcctName, onDemandMetadata.Asset, 1, "100.0", flowToken, 0,
+			iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+
+		_, err = c.MintOnDemand(ctx, buyerAcctName, onDemandMetadata.Asset, 1, "100.0", flowToken, 0,
+			iinft.MintOnDemandParameters{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many editions requested")
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 5)
+	})
+}
+
+func TestClient_MintBatch(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	artistAcct := client.Account(user1AccountName)
+
+	// user2 has a DigitalArt collection, user3 doesn't
+
+	collectorAcct := client.Account(user2AccountName)
+	testscripts.FundAccountWithFlow(t, se, collectorAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(user2AccountName).Test(t).AssertSuccess()
+
+	noCollectionAcct := client.Account(user3AccountName)
+
+	var masterIDs []string
+	for i := 0; i < 3; i++ {
+		metadata := SampleMetadata(2)
+		metadata.Asset = fmt.Sprintf("did:sequel:asset-%d", i)
+		require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+		masterIDs = append(masterIDs, metadata.Asset)
+	}
+
+	t.Run("Should be able to mint editions of many masters", func(t *testing.T) {
+		items := []*iinft.BatchMintItem{
+			{MasterID: masterIDs[0], Recipient: collectorAcct.Address, ModID: 1},
+			{MasterID: masterIDs[1], Recipient: collectorAcct.Address, ModID: 2},
+			{MasterID: "did:sequel:unknown", Recipient: collectorAcct.Address, ModID: 3},
+			{MasterID: masterIDs[2], Recipient: noCollectionAcct.Address, ModID: 4},
+			{MasterID: masterIDs[2], Recipient: collectorAcct.Address},
+			{MasterID: masterIDs[0], Recipient: collectorAcct.Address},
+			{MasterID: masterIDs[0], Recipient: collectorAcct.Address},
+		}
+
+		results := c.MintBatch(ctx, items, iinft.BatchMintOptions{ChunkSize: 3})
+		require.Len(t, results, len(items))
+
+		type outcome struct {
+			nftID   uint64
+			edition uint64
+			err     error
+		}
+		expected := []outcome{
+			{0, 1, nil},
+			{1, 1, nil},
+			{0, 0, iinft.ErrNotMinted},
+			{0, 0, iinft.ErrNotMinted},
+			{2, 1, nil},
+			{3, 2, nil},
+			{0, 0, iinft.ErrNotMinted},
+		}
+
+		for i, r := range results {
+			assert.Equal(t, items[i], r.Item)
+			assert.Equal(t, expected[i].err, r.Err, "item %d", i)
+			assert.Equal(t, expected[i].nftID, r.NFTID, "item %d", i)
+			assert.Equal(t, expected[i].edition, r.Edition, "item %d", i)
+			assert.NotEqual(t, flow.EmptyID, r.TransactionID)
+		}
+
+		// items were minted in three transactions
+
+		assert.Equal(t, results[0].TransactionID, results[2].TransactionID)
+		assert.NotEqual(t, results[2].TransactionID, results[3].TransactionID)
+		assert.NotEqual(t, results[5].TransactionID, results[6].TransactionID)
+
+		checkDigitalArtCollectionLen(t, se, collectorAcct.Address.String(), 4)
+	})
+
+	t.Run("Should split chunks that exceed the computation limit", func(t *testing.T) {
+		items := []*iinft.BatchMintItem{
+			{MasterID: masterIDs[1], Recipient: collectorAcct.Address},
+			{MasterID: masterIDs[2], Recipient: collectorAcct.Address},
+		}
+
+		// set the limit between the computation used by one and two items,
+		// so that the chunk fails and each half succeeds
+
+		one, err := c.DryRunMintBatch(ctx, items[:1])
+		require.NoError(t, err)
+		require.NoError(t, one.Error)
+		two, err := c.DryRunMintBatch(ctx, items)
+		require.NoError(t, err)
+		require.NoError(t, two.Error)
+		require.Less(t, one.ComputationUsed, two.ComputationUsed)
+
+		gasLimit := (one.ComputationUsed + two.ComputationUsed) / 2
+
+		results := c.MintBatch(ctx, items, iinft.BatchMintOptions{GasLimit: gasLimit})
+		require.Len(t, results, 2)
+
+		for _, r := range results {
+			require.NoError(t, r.Err)
+			assert.Equal(t, uint64(2), r.Edition)
+		}
+		assert.NotEqual(t, results[0].TransactionID, results[1].TransactionID)
+
+		checkDigitalArtCollectionLen(t, se, collectorAcct.Address.String(), 6)
+	})
+}
+
+func TestClient_ListBatch(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	artistAcct := client.Account(platformAccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName)
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	buyerAcctName := user2AccountName
+	testscripts.FundAccountWithFlow(t, se, client.Account(buyerAcctName).Address, "1000.0")
+
+	metadata := SampleMetadata(5)
+	require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+	nftIDs, err := c.MintEdition(ctx, metadata.Asset, 5, sellerAcct.Address)
+	require.NoError(t, err)
+	require.Len(t, nftIDs, 5)
+
+	link := "link"
+	items := []*iinft.BatchListItem{
+		{TokenID: nftIDs[0], Price: "10.0", Token: flowToken, MetadataLink: &link},
+		{TokenID: nftIDs[1], Price: "20.0", Token: flowToken},
+		{TokenID: 1000, Price: "30.0", Token: flowToken},
+		{TokenID: nftIDs[2], Price: "40.0", Token: flowToken},
+		{TokenID: nftIDs[3], Price: "50.0", Token: flowToken},
+	}
+
+	var results []*iinft.BatchListResult
+
+	t.Run("Should allow cleanup when the first item is skipped", func(t *testing.T) {
+		skipped := c.ListBatch(ctx, sellerAcctName, []*iinft.BatchListItem{
+			{TokenID: 1000, Price: "10.0", Token: flowToken},
+			{TokenID: nftIDs[4], Price: "10.0", Token: flowToken},
+		}, iinft.BatchListOptions{})
+		require.Len(t, skipped, 2)
+		assert.Equal(t, iinft.ErrNotListed, skipped[0].Err)
+		require.NoError(t, skipped[1].Err)
+
+		val, err := client.Script(`
+		import SequelMarketplace from 0x179b6b1cb6755e31
+
+		access(all) fun main(seller: Address): Bool {
+			return SequelMarketplace.canCleanup(storefrontAddress: seller)
+		}
+		`).
+			Argument(cadence.NewAddress(sellerAcct.Address)).
+			RunReturns(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewBool(true), val)
+	})
+
+	t.Run("Should be able to list many tokens", func(t *testing.T) {
+		results = c.ListBatch(ctx, sellerAcctName, items, iinft.BatchListOptions{ChunkSize: 3})
+		require.Len(t, results, len(items))
+
+		for i, r := range results {
+			assert.Equal(t, items[i], r.Item)
+			assert.NotEqual(t, flow.EmptyID, r.TransactionID)
+			if i == 2 {
+				assert.Equal(t, iinft.ErrNotListed, r.Err)
+				continue
+			}
+			require.NoError(t, r.Err, "item %d", i)
+			assert.NotZero(t, r.ListingID)
+		}
+
+		// items were listed in two transactions
+
+		assert.Equal(t, results[0].TransactionID, results[2].TransactionID)
+		assert.NotEqual(t, results[2].TransactionID, results[3].TransactionID)
+
+		nftID, err := c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, results[3].ListingID, flowToken, nil)
+		require.NoError(t, err)
+		assert.Equal(t, nftIDs[2], nftID)
+	})
+
+	t.Run("Should be able to withdraw many listings", func(t *testing.T) {
+		listingIDs := []uint64{results[0].ListingID, results[1].ListingID, results[3].ListingID, results[4].ListingID}
+
+		withdrawn := c.WithdrawBatch(ctx, sellerAcctName, listingIDs, iinft.BatchListOptions{})
+		require.Len(t, withdrawn, len(listingIDs))
+
+		for i, r := range withdrawn {
+			assert.Equal(t, listingIDs[i], r.ListingID)
+			if i == 2 {
+				// the listing was purchased
+				assert.Equal(t, iinft.ErrNotWithdrawn, r.Err)
+				continue
+			}
+			assert.NoError(t, r.Err, "listing %d", i)
+		}
+
+		_, err := c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, results[0].ListingID, flowToken, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("Should split chunks that exceed the computation limit", func(t *testing.T) {
+		results := c.ListBatch(ctx, sellerAcctName, []*iinft.BatchListItem{
+			{TokenID: nftIDs[0], Price: "10.0", Token: flowToken},
+			{TokenID: nftIDs[1], Price: "10.0", Token: flowToken},
+		}, iinft.BatchListOptions{GasLimit: 90})
+		require.Len(t, results, 2)
+
+		for _, r := range results {
+			require.NoError(t, r.Err)
+		}
+		assert.NotEqual(t, results[0].TransactionID, results[1].TransactionID)
+	})
+}
+
+func TestClient_GetMasters(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	artistAcct := client.Account(user1AccountName)
+	testscripts.FundAccountWithFlow(t, se, artistAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(user1AccountName).Test(t).AssertSuccess()
+
+	profile := BasicEvergreenProfile(artistAcct.Address)
+
+	openMetadata := SampleMetadata(3)
+	openMetadata.Asset = "did:sequel:asset-open"
+	require.NoError(t, c.SealMaster(ctx, openMetadata, profile))
+	_, err = c.MintEdition(ctx, openMetadata.Asset, 1, artistAcct.Address)
+	require.NoError(t, err)
+	require.NoError(t, c.PauseMaster(ctx, openMetadata.Asset))
+
+	closedMetadata := SampleMetadata(1)
+	closedMetadata.Asset = "did:sequel:asset-closed"
+	require.NoError(t, c.SealMaster(ctx, closedMetadata, profile))
+	_, err = c.MintEdition(ctx, closedMetadata.Asset, 1, artistAcct.Address)
+	require.NoError(t, err)
+
+	t.Run("Should return a sealed master", func(t *testing.T) {
+		master, err := c.GetMaster(ctx, openMetadata.Asset)
+		require.NoError(t, err)
+		require.NotNil(t, master)
+
+		assert.Equal(t, openMetadata.Asset, master.AssetID)
+		assert.Equal(t, openMetadata, master.Metadata)
+		assert.Equal(t, profile, master.Profile)
+		assert.Equal(t, uint64(2), master.NextEdition)
+		assert.Equal(t, uint64(2), master.AvailableEditions())
+		assert.False(t, master.Closed)
+		assert.True(t, master.Paused)
+	})
+
+	t.Run("Should return nil for unknown masters", func(t *testing.T) {
+		master, err := c.GetMaster(ctx, "did:sequel:unknown")
+		require.NoError(t, err)
+		assert.Nil(t, master)
+	})
+
+	t.Run("Should return many masters at once", func(t *testing.T) {
+		masters, err := c.GetMasters(ctx, []string{openMetadata.Asset, "did:sequel:unknown", closedMetadata.Asset})
+		require.NoError(t, err)
+		require.Len(t, masters, 2)
+
+		closed := masters[closedMetadata.Asset]
+		require.NotNil(t, closed)
+		assert.True(t, closed.Closed)
+		assert.False(t, closed.Paused)
+		assert.Nil(t, closed.Metadata)
+		assert.Nil(t, closed.Profile)
+		assert.Zero(t, closed.AvailableEditions())
+	})
+
+	t.Run("Should expose master state without the admin account", func(t *testing.T) {
+		_, err := client.Script(`
+		import DigitalArt from 0x179b6b1cb6755e31
+
+		access(all) fun main(openId: String, closedId: String) {
+			assert(DigitalArt.isSealed(masterId: openId), message: "master is not sealed")
+			assert(DigitalArt.availableEditions(masterId: openId) == 2, message: "wrong number of available editions")
+			assert(DigitalArt.evergreenProfile(masterId: openId) != nil, message: "missing profile")
+
+			assert(DigitalArt.isSealed(masterId: closedId), message: "closed master is not sealed")
+			assert(DigitalArt.availableEditions(masterId: closedId) == 0, message: "closed master has editions")
+			assert(DigitalArt.evergreenProfile(masterId: closedId) == nil, message: "closed master has profile")
+
+			assert(!DigitalArt.isSealed(masterId: "unknown"), message: "unknown master is sealed")
+			assert(DigitalArt.availableEditions(masterId: "unknown") == 0, message: "unknown master has editions")
+			assert(DigitalArt.getMaster(masterId: "unknown") == nil, message: "unknown master found")
+		}
+		`).
+			StringArgument(openMetadata.Asset).
+			StringArgument(closedMetadata.Asset).
+			RunReturns(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_CollectorRole(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	artistAcct := client.Account(platformAccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName)
+
+	firstCollectorAcct := client.Account(user1AccountName)
+	testscripts.FundAccountWithFlow(t, se, firstCollectorAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(user1AccountName).Test(t).AssertSuccess()
+	testscripts.SetUpRoyaltyReceivers(t, se, user1AccountName, user1AccountName)
+
+	sellerAcct := client.Account(user2AccountName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "100.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(user2AccountName).Test(t).AssertSuccess()
+
+	buyerAcct := client.Account(user3AccountName)
+	testscripts.FundAccountWithFlow(t, se, buyerAcct.Address, "1000.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(user3AccountName).Test(t).AssertSuccess()
+
+	metadata := SampleMetadata(1)
+	profile := &evergreen.Profile{
+		ID: "did:sequel:evergreen-collector",
+		Roles: []*evergreen.Role{
+			{
+				ID:                        evergreen.RoleArtist,
+				InitialSaleCommission:     evergreen.MustParseUFix64("1.0"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
+				Address:                   artistAcct.Address,
+			},
+			{
+				ID:                        evergreen.RoleCollector,
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.1"),
+			},
+		},
+	}
+	require.NoError(t, c.SealMaster(ctx, metadata, profile))
+
+	nftIDs, err := c.MintEdition(ctx, metadata.Asset, 1, firstCollectorAcct.Address)
+	require.NoError(t, err)
+	nftID := nftIDs[0]
+
+	t.Run("Should not record owner history on transfer", func(t *testing.T) {
+		require.NoError(t, c.Transfer(ctx, user1AccountName, nftID, sellerAcct.Address))
+
+		history, err := c.GetOwnerHistory(ctx, sellerAcct.Address, nftID)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+
+		require.NoError(t, c.Transfer(ctx, user2AccountName, nftID, firstCollectorAcct.Address))
+	})
+
+	t.Run("Should record owner history on sale", func(t *testing.T) {
+		listingID, err := c.ListToken(ctx, user1AccountName, nftID, "5.0", flowToken, nil)
+		require.NoError(t, err)
+
+		_, err = c.BuyToken(ctx, user2AccountName, firstCollectorAcct.Address, listingID, flowToken, nil)
+		require.NoError(t, err)
+
+		history, err := c.GetOwnerHistory(ctx, sellerAcct.Address, nftID)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Address{firstCollectorAcct.Address, sellerAcct.Address}, history)
+
+		assert.Equal(t, []flow.Address{firstCollectorAcct.Address}, evergreen.Collectors(history, sellerAcct.Address))
+	})
+
+	t.Run("Should pay collectors in secondary sales", func(t *testing.T) {
+		listingID, err := c.ListToken(ctx, user2AccountName, nftID, "100.0", flowToken, nil)
+		require.NoError(t, err)
+
+		collectorBalance := testscripts.GetFlowBalance(t, se, firstCollectorAcct.Address)
+
+		_, err = c.BuyToken(ctx, user3AccountName, sellerAcct.Address, listingID, flowToken, nil)
+		require.NoError(t, err)
+
+		assert.InDelta(t, collectorBalance+10.0, testscripts.GetFlowBalance(t, se, firstCollectorAcct.Address), 0.00000001)
+
+		history, err := c.GetOwnerHistory(ctx, buyerAcct.Address, nftID)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Address{firstCollectorAcct.Address, sellerAcct.Address, buyerAcct.Address}, history)
+	})
+
+	t.Run("Should exclude the collector role from royalties", func(t *testing.T) {
+		val, err := client.Script(`
+		import MetadataViews from 0xf8d6e0586b0a20c7
+		import DigitalArt from 0x179b6b1cb6755e31
+
+		access(all) fun main(address: Address, tokenId: UInt64): Int {
+			let collection = getAccount(address).capabilities.borrow<&DigitalArt.Collection>(DigitalArt.CollectionPublicPath)!
+			let royalties = collection.borrowDigitalArt(id: tokenId)!.resolveView(Type<MetadataViews.Royalties>())! as! MetadataViews.Royalties
+			return royalties.getRoyalties().length
+		}
+		`).
+			Argument(cadence.NewAddress(buyerAcct.Address)).
+			UInt64Argument(nftID).
+			RunReturns(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewInt(1), val)
+	})
+
+	t.Run("Should cap owner history", func(t *testing.T) {
+		// sell the token through 12 new accounts and back to the buyer
+
+		_ = client.Transaction(`
+import FungibleToken from 0xee82856bf20e2aa6
+import NonFungibleToken from 0xf8d6e0586b0a20c7
+import NFTStorefront from 0xf8d6e0586b0a20c7
+import FlowToken from 0x0ae53cb6e3f42a79
+import DigitalArt from 0x179b6b1cb6755e31
+import SequelMarketplace from 0x179b6b1cb6755e31
+
+transaction(tokenId: UInt64, numOwners: Int) {
+    prepare(signer: auth(Storage, Capabilities) &Account) {
+        let vault = signer.storage.borrow<auth(FungibleToken.Withdraw) &FlowToken.Vault>(from: /storage/flowTokenVault)!
+
+        var seller: auth(Storage, Capabilities) &Account = signer
+        var i = 0
+        while i <= numOwners {
+            var buyer: auth(Storage, Capabilities) &Account = signer
+            if i < numOwners {
+                buyer = Account(payer: signer)
+                buyer.storage.save(<-DigitalArt.createEmptyCollection(nftType: Type<@DigitalArt.NFT>()), to: DigitalArt.CollectionStoragePath)
+            }
+
+            if seller.storage.type(at: NFTStorefront.StorefrontStoragePath) == nil {
+                seller.storage.save(<-NFTStorefront.createStorefront(), to: NFTStorefront.StorefrontStoragePath)
+            }
+            let storefront = seller.storage.borrow<auth(NFTStorefront.CreateListing) &NFTStorefront.Storefront>(from: NFTStorefront.StorefrontStoragePath)!
+
+            let listingID = SequelMarketplace.listToken(
+                storefront: storefront,
+                nftProviderCapability: seller.capabilities.storage.issue<auth(NonFungibleToken.Withdraw) &DigitalArt.Collection>(DigitalArt.CollectionStoragePath),
+                nftType: Type<@DigitalArt.NFT>(),
+                nftID: tokenId,
+                sellerVaultPath: /public/flowTokenReceiver,
+                paymentVaultType: Type<@FlowToken.Vault>(),
+                price: 1.0,
+                extraRoles: [],
+                metadataLink: nil
+            )
+
+            let item <- SequelMarketplace.buyToken(
+                storefrontAddress: seller.address,
+                storefront: storefront,
+                listingID: listingID,
+                listing: storefront.borrowListing(listingResourceID: listingID)!,
+                paymentVault: <-vault.withdraw(amount: 1.0),
+                buyerAddress: buyer.address,
+                metadataLink: nil
+            )
+            buyer.storage.borrow<&DigitalArt.Collection>(from: DigitalArt.CollectionStoragePath)!.deposit(token: <-item)
+
+            seller = buyer
+            i = i + 1
+        }
+    }
+}`).
+			SignProposeAndPayAs(user3AccountName).
+			UInt64Argument(nftID).
+			Argument(cadence.NewInt(12)).
+			Test(t).
+			AssertSuccess()
+
+		history, err := c.GetOwnerHistory(ctx, buyerAcct.Address, nftID)
+		require.NoError(t, err)
+		require.Len(t, history, 11)
+		assert.Equal(t, buyerAcct.Address, history[10])
+		assert.NotContains(t, history, firstCollectorAcct.Address)
+		assert.NotContains(t, history, sellerAcct.Address)
+
+		assert.Len(t, evergreen.Collectors(history, buyerAcct.Address), 10)
+	})
+}
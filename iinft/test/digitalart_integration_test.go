@@ -62,20 +62,20 @@ func TestDigitalArt_Integration_MintOnDemand_ExampleToken(t *testing.T) {
 		Roles: []*evergreen.Role{
 			{
 				ID:                        evergreen.RoleArtist,
-				InitialSaleCommission:     0.9,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.9"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   artistAcct.Address,
 			},
 			{
 				ID:                        evergreen.RolePlatform,
-				InitialSaleCommission:     0.05,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.05"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   platformAcct.Address,
 			},
 			{
 				ID:                        "ClimateActionFund",
-				InitialSaleCommission:     0.05,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.05"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   greenAcct.Address,
 			},
 		},
@@ -83,10 +83,13 @@ func TestDigitalArt_Integration_MintOnDemand_ExampleToken(t *testing.T) {
 
 	t.Run("Should be able to mint a token on demand (master not sealed)", func(t *testing.T) {
 
-		_ = client.Transaction(se.GetCustomScript("digitalart_mint_on_demand", iinft.MintOnDemandParameters{
+		script, err := iinft.GetMintOnDemandScript(se, "digitalart_mint_on_demand", iinft.MintOnDemandParameters{
 			Metadata: metadata,
 			Profile:  profile,
-		})).
+		})
+		require.NoError(t, err)
+
+		_ = client.Transaction(script).
 			PayloadSigner(buyerAcctName).
 			SignProposeAndPayAs(adminAccountName).
 			StringArgument(metadata.Asset).
@@ -138,10 +141,13 @@ func TestDigitalArt_Integration_MintOnDemand_ExampleToken(t *testing.T) {
 
 	t.Run("Should be able to mint a token on demand (master sealed, metadata provided)", func(t *testing.T) {
 
-		_ = client.Transaction(se.GetCustomScript("digitalart_mint_on_demand", iinft.MintOnDemandParameters{
+		script, err := iinft.GetMintOnDemandScript(se, "digitalart_mint_on_demand", iinft.MintOnDemandParameters{
 			Metadata: metadata,
 			Profile:  profile,
-		})).
+		})
+		require.NoError(t, err)
+
+		_ = client.Transaction(script).
 			PayloadSigner(buyerAcctName).
 			SignProposeAndPayAs(adminAccountName).
 			StringArgument(metadata.Asset).
@@ -291,20 +297,20 @@ func TestDigitalArt_Integration_MintOnDemand_Flow(t *testing.T) {
 		Roles: []*evergreen.Role{
 			{
 				ID:                        evergreen.RoleArtist,
-				InitialSaleCommission:     0.9,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.9"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   artistAcct.Address,
 			},
 			{
 				ID:                        evergreen.RolePlatform,
-				InitialSaleCommission:     0.05,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.05"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   platformAcct.Address,
 			},
 			{
 				ID:                        "ClimateActionFund",
-				InitialSaleCommission:     0.05,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.05"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   greenAcct.Address,
 			},
 		},
@@ -312,10 +318,13 @@ func TestDigitalArt_Integration_MintOnDemand_Flow(t *testing.T) {
 
 	t.Run("Should be able to mint a token on demand (master not sealed)", func(t *testing.T) {
 
-		_ = client.Transaction(se.GetCustomScript("digitalart_mint_on_demand", iinft.MintOnDemandParameters{
+		script, err := iinft.GetMintOnDemandScript(se, "digitalart_mint_on_demand", iinft.MintOnDemandParameters{
 			Metadata: metadata,
 			Profile:  profile,
-		})).
+		})
+		require.NoError(t, err)
+
+		_ = client.Transaction(script).
 			PayloadSigner(buyerAcctName).
 			SignProposeAndPayAs(adminAccountName).
 			StringArgument(metadata.Asset).
@@ -367,10 +376,13 @@ func TestDigitalArt_Integration_MintOnDemand_Flow(t *testing.T) {
 
 	t.Run("Should be able to mint a token on demand (master sealed)", func(t *testing.T) {
 
-		_ = client.Transaction(se.GetCustomScript("digitalart_mint_on_demand", iinft.MintOnDemandParameters{
+		script, err := iinft.GetMintOnDemandScript(se, "digitalart_mint_on_demand", iinft.MintOnDemandParameters{
 			Metadata: metadata,
 			Profile:  profile,
-		})).
+		})
+		require.NoError(t, err)
+
+		_ = client.Transaction(script).
 			PayloadSigner(buyerAcctName).
 			SignProposeAndPayAs(adminAccountName).
 			StringArgument(metadata.Asset).
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/piprate/sequel-flow-contracts/iinft"
 	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
@@ -124,6 +125,31 @@ func TestDigitalArt_sealMaster(t *testing.T) {
 			AssertSuccess()
 	})
 
+	t.Run("Should be able to seal new digital art master with JSON-Cadence arguments", func(t *testing.T) {
+
+		metadata := SampleMetadata(4)
+		metadata.Asset = "did:sequel:asset-jsoncdc"
+
+		metadataJSON, err := metadata.MarshalJSONCDC(se.ContractAddress("DigitalArt"))
+		require.NoError(t, err)
+		profileJSON, err := profile.MarshalJSONCDC(se.ContractAddress("Evergreen"))
+		require.NoError(t, err)
+
+		// decode the values without type information, the same way Flow CLI does with --args-json
+
+		metadataVal, err := jsoncdc.Decode(nil, metadataJSON)
+		require.NoError(t, err)
+		profileVal, err := jsoncdc.Decode(nil, profileJSON)
+		require.NoError(t, err)
+
+		_ = client.Transaction(se.GetStandardScript("master_seal")).
+			Argument(metadataVal).
+			Argument(profileVal).
+			SignProposeAndPayAs(adminAccountName).
+			Test(t).
+			AssertSuccess()
+	})
+
 	t.Run("Shouldn't be able to seal the same digital art master twice", func(t *testing.T) {
 
 		metadata := SampleMetadata(4)
@@ -457,6 +483,172 @@ func TestDigitalArt_isClosed(t *testing.T) {
 	})
 }
 
+func TestDigitalArt_masterLifecycle(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	artistAcct := client.Account(user1AccountName)
+
+	userAcct := client.Account(user2AccountName)
+	testscripts.FundAccountWithFlow(t, se, userAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(user2AccountName).Test(t).AssertSuccess()
+
+	profile := BasicEvergreenProfile(artistAcct.Address)
+
+	mint := func(assetID string) splash.TransactionResult {
+		return se.NewTransaction("digitalart_mint_edition").
+			SignProposeAndPayAs(adminAccountName).
+			StringArgument(assetID).
+			UInt64Argument(1).
+			Argument(cadence.NewAddress(userAcct.Address)).
+			Test(t)
+	}
+
+	t.Run("Should be able to update a master before the first mint", func(t *testing.T) {
+		metadata := SampleMetadata(2)
+		metadata.Asset = "did:sequel:asset-update"
+
+		require.NoError(t, c.SealMaster(ctx, metadata, profile))
+
+		metadata.Name = "Fixed Name"
+		metadata.MaxEdition = 3
+
+		profileVal, err := evergreen.ProfileToCadence(profile, se.ContractAddress("Evergreen"))
+		require.NoError(t, err)
+
+		_ = se.NewTransaction("master_update").
+			SignProposeAndPayAs(adminAccountName).
+			Argument(iinft.DigitalArtMetadataToCadence(metadata, se.ContractAddress("DigitalArt"))).
+			Argument(profileVal).
+			Test(t).
+			AssertSuccess().
+			AssertEmitEvent(splash.NewTestEvent("A.179b6b1cb6755e31.DigitalArt.MasterUpdated", map[string]interface{}{
+				"asset": "did:sequel:asset-update",
+			}))
+
+		_ = mint(metadata.Asset).AssertSuccess()
+
+		meta, err := se.NewScript("digitalart_get_metadata").
+			Argument(cadence.NewAddress(userAcct.Address)).
+			UInt64Argument(0).
+			RunReturns(ctx)
+		require.NoError(t, err)
+		updated, err := iinft.DigitalArtMetadataFromCadence(meta)
+		require.NoError(t, err)
+		assert.Equal(t, "Fixed Name", updated.Name)
+		assert.Equal(t, uint64(3), updated.MaxEdition)
+
+		// the master can't be updated once an edition is minted
+
+		err = c.UpdateMaster(ctx, metadata, profile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master already minted")
+
+		err = c.UpdateMaster(ctx, SampleMetadata(2), profile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master not found")
+	})
+
+	t.Run("Should be able to pause and resume a master", func(t *testing.T) {
+		metadata := SampleMetadata(2)
+		metadata.Asset = "did:sequel:asset-pause"
+
+		require.NoError(t, c.SealMaster(ctx, metadata, profile))
+
+		_ = se.NewTransaction("master_pause").
+			SignProposeAndPayAs(adminAccountName).
+			StringArgument(metadata.Asset).
+			Test(t).
+			AssertSuccess().
+			AssertEmitEvent(splash.NewTestEvent("A.179b6b1cb6755e31.DigitalArt.MasterPaused", map[string]interface{}{
+				"asset": "did:sequel:asset-pause",
+			}))
+
+		err := c.PauseMaster(ctx, metadata.Asset)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master already paused")
+
+		_ = mint(metadata.Asset).AssertFailure("Master paused")
+
+		_ = se.NewTransaction("master_resume").
+			SignProposeAndPayAs(adminAccountName).
+			StringArgument(metadata.Asset).
+			Test(t).
+			AssertSuccess().
+			AssertEmitEvent(splash.NewTestEvent("A.179b6b1cb6755e31.DigitalArt.MasterResumed", map[string]interface{}{
+				"asset": "did:sequel:asset-pause",
+			}))
+
+		err = c.ResumeMaster(ctx, metadata.Asset)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master not paused")
+
+		_ = mint(metadata.Asset).AssertSuccess()
+	})
+
+	t.Run("Should be able to close a master early", func(t *testing.T) {
+		metadata := SampleMetadata(3)
+		metadata.Asset = "did:sequel:asset-close"
+
+		require.NoError(t, c.SealMaster(ctx, metadata, profile))
+
+		_ = mint(metadata.Asset).AssertSuccess()
+
+		require.NoError(t, c.PauseMaster(ctx, metadata.Asset))
+
+		_ = se.NewTransaction("master_close").
+			SignProposeAndPayAs(adminAccountName).
+			StringArgument(metadata.Asset).
+			Test(t).
+			AssertSuccess().
+			AssertEmitEvent(splash.NewTestEvent("A.179b6b1cb6755e31.DigitalArt.MasterClosed", map[string]interface{}{
+				"asset":            "did:sequel:asset-close",
+				"unmintedEditions": "2",
+			}))
+
+		_, err := client.Script(`
+		import DigitalArt from 0x179b6b1cb6755e31
+
+		access(all) fun main(masterId: String) {
+			assert(DigitalArt.isClosed(masterId: masterId), message: "master is not closed")
+			assert(!DigitalArt.isPaused(masterId: masterId), message: "master is paused")
+		}
+		`).
+			StringArgument(metadata.Asset).
+			RunReturns(ctx)
+		require.NoError(t, err)
+
+		// a closed master can't be minted from, updated, reopened or sealed again
+
+		_ = mint(metadata.Asset).AssertFailure("too many editions requested")
+
+		err = c.CloseMaster(ctx, metadata.Asset)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master already closed")
+
+		err = c.PauseMaster(ctx, metadata.Asset)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master closed")
+
+		err = c.UpdateMaster(ctx, metadata, profile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master closed")
+
+		err = c.SealMaster(ctx, metadata, profile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Master already sealed")
+	})
+}
+
 func TestDigitalArt_NFT(t *testing.T) {
 	client, err := splash.NewInMemoryTestConnector("../..", true)
 	require.NoError(t, err)
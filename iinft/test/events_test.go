@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventDecoder(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	decoder := iinft.NewEventDecoder(se)
+
+	assert.Equal(t, []string{
+		"A.179b6b1cb6755e31.DigitalArt.Deposit",
+		"A.179b6b1cb6755e31.DigitalArt.MasterClosed",
+		"A.179b6b1cb6755e31.DigitalArt.MasterPaused",
+		"A.179b6b1cb6755e31.DigitalArt.MasterResumed",
+		"A.179b6b1cb6755e31.DigitalArt.MasterUpdated",
+		"A.179b6b1cb6755e31.DigitalArt.Minted",
+		"A.179b6b1cb6755e31.DigitalArt.Withdraw",
+		"A.179b6b1cb6755e31.SequelAuctions.AuctionCancelled",
+		"A.179b6b1cb6755e31.SequelAuctions.AuctionCreated",
+		"A.179b6b1cb6755e31.SequelAuctions.AuctionSettled",
+		"A.179b6b1cb6755e31.SequelAuctions.BidPlaced",
+		"A.179b6b1cb6755e31.SequelMarketplace.ListingCleanedUp",
+		"A.179b6b1cb6755e31.SequelMarketplace.TokenListed",
+		"A.179b6b1cb6755e31.SequelMarketplace.TokenSold",
+		"A.179b6b1cb6755e31.SequelMarketplace.TokenWithdrawn",
+		"A.179b6b1cb6755e31.SequelOffers.OfferAccepted",
+		"A.179b6b1cb6755e31.SequelOffers.OfferCancelled",
+		"A.179b6b1cb6755e31.SequelOffers.OfferMade",
+	}, decoder.EventTypes())
+
+	ctx := context.Background()
+
+	platformAcct := client.Account(platformAccountName)
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	testscripts.SetUpRoyaltyReceivers(t, se, sellerAcctName, sellerAcctName)
+
+	metadata := SampleMetadata(1)
+	profile := PrimaryOnlyEvergreenProfile(sellerAcct.Address, platformAcct.Address)
+
+	_ = testscripts.CreateSealDigitalArtTx(t, se, client, metadata, profile).
+		SignProposeAndPayAs(adminAccountName).
+		Test(t).
+		AssertSuccess()
+
+	res, err := se.NewTransaction("digitalart_mint_edition").
+		SignProposeAndPayAs(adminAccountName).
+		StringArgument(metadata.Asset).
+		UInt64Argument(1).
+		Argument(cadence.NewAddress(sellerAcct.Address)).
+		RunE(ctx)
+	require.NoError(t, err)
+
+	minted, err := decoder.MintedEvents(res.Events)
+	require.NoError(t, err)
+	require.Len(t, minted, 1)
+	assert.Equal(t, &iinft.MintedEvent{
+		ID:      0,
+		Asset:   metadata.Asset,
+		Edition: 1,
+		ModID:   0,
+	}, minted[0])
+
+	var deposit *iinft.DepositEvent
+	for _, ev := range res.Events {
+		val, err := decoder.Decode(ev)
+		if err == nil {
+			if d, ok := val.(*iinft.DepositEvent); ok {
+				deposit = d
+			}
+		} else {
+			require.ErrorIs(t, err, iinft.ErrUnknownEvent)
+		}
+	}
+	require.NotNil(t, deposit)
+	assert.Equal(t, &sellerAcct.Address, deposit.To)
+
+	res, err = se.NewTransaction("marketplace_list").
+		SignProposeAndPayAs(sellerAcctName).
+		UInt64Argument(minted[0].ID).
+		UFix64Argument("200.0").
+		Argument(cadence.NewAddress(se.ContractAddress("FlowToken"))).
+		StringArgument("FlowToken").
+		Argument(cadence.NewOptional(nil)).
+		RunE(ctx)
+	require.NoError(t, err)
+
+	listed, err := decoder.TokenListedEvents(res.Events)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	ev := listed[0]
+	assert.Equal(t, sellerAcct.Address, ev.StorefrontAddress)
+	assert.NotZero(t, ev.ListingID)
+	assert.Equal(t, "A.179b6b1cb6755e31.DigitalArt.NFT", ev.NFTType)
+	assert.Equal(t, minted[0].ID, ev.NFTID)
+	assert.Equal(t, "A.0ae53cb6e3f42a79.FlowToken.Vault", ev.PaymentVaultType)
+	assert.Equal(t, evergreen.MustParseUFix64("200.0"), ev.Price)
+	assert.Equal(t, metadata.Asset, ev.Asset)
+	assert.Nil(t, ev.MetadataLink)
+	assert.Equal(t, []*evergreen.Payment{
+		{Role: "Artist", Receiver: flow.HexToAddress("0xe03daebed8ca0615"), Amount: evergreen.MustParseUFix64("10.0"), Rate: evergreen.MustParseUFix64("0.05")},
+		{Role: "Owner", Receiver: flow.HexToAddress("0xe03daebed8ca0615"), Amount: evergreen.MustParseUFix64("190.0"), Rate: evergreen.MustParseUFix64("0.95")},
+	}, ev.Payments)
+}
@@ -116,16 +116,16 @@ func TestEvergreen_Profile_buildRoyalties(t *testing.T) {
 			{
 				ID:                        "test1",
 				Description:               "Test Role 1",
-				InitialSaleCommission:     0.8,
-				SecondaryMarketCommission: 0.05,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
 				Address:                   user1Acct.Address,
 				ReceiverPath:              "/public/exampleTokenReceiver",
 			},
 			{
 				ID:                        "test2",
 				Description:               "Test Role 2",
-				InitialSaleCommission:     0.2,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.2"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   user2Acct.Address,
 			},
 		},
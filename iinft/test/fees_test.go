@@ -0,0 +1,201 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_FeePolicy(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	platformAcct := client.Account(platformAccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName)
+
+	artistAcct := client.Account(user3AccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, user3AccountName, adminAccountName)
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	buyerAcctName := user2AccountName
+	testscripts.FundAccountWithFlow(t, se, client.Account(buyerAcctName).Address, "1000.0")
+
+	flowVaultType := fmt.Sprintf("A.%s.FlowToken.Vault", flowToken.Address.Hex())
+
+	defaultFee := &evergreen.Role{
+		ID:                        evergreen.RolePlatform,
+		InitialSaleCommission:     evergreen.MustParseUFix64("0.0"),
+		SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
+		Address:                   platformAcct.Address,
+	}
+	flowFee := &evergreen.Role{
+		ID:                        evergreen.RolePlatform,
+		Description:               "FLOW sales",
+		InitialSaleCommission:     evergreen.MustParseUFix64("0.0"),
+		SecondaryMarketCommission: evergreen.MustParseUFix64("0.02"),
+		Address:                   platformAcct.Address,
+	}
+
+	t.Run("Should be able to set and read fees", func(t *testing.T) {
+		policy, err := c.GetFeePolicy(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, policy.Default)
+		assert.Empty(t, policy.Fees)
+
+		require.NoError(t, c.SetFee(ctx, nil, defaultFee))
+		require.NoError(t, c.SetFee(ctx, &flowToken, flowFee))
+
+		policy, err = c.GetFeePolicy(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, defaultFee, policy.Default)
+		assert.Equal(t, map[string]*evergreen.Role{flowVaultType: flowFee}, policy.Fees)
+		assert.Equal(t, flowFee, policy.Fee(flowVaultType))
+		assert.Equal(t, defaultFee, policy.Fee("A.f8d6e0586b0a20c7.ExampleToken.Vault"))
+	})
+
+	t.Run("Should charge the fee for the payment vault type", func(t *testing.T) {
+		metadata := SampleMetadata(1)
+		require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+		nftIDs, err := c.MintEdition(ctx, metadata.Asset, 1, sellerAcct.Address)
+		require.NoError(t, err)
+
+		listingID, err := c.ListToken(ctx, sellerAcctName, nftIDs[0], "100.0", flowToken, nil)
+		require.NoError(t, err)
+
+		platformBalance := testscripts.GetFlowBalance(t, se, platformAcct.Address)
+		artistBalance := testscripts.GetFlowBalance(t, se, artistAcct.Address)
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		_, err = c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, listingID, flowToken, nil)
+		require.NoError(t, err)
+
+		assert.InDelta(t, platformBalance+2.0, testscripts.GetFlowBalance(t, se, platformAcct.Address), 0.00000001)
+		assert.InDelta(t, artistBalance+5.0, testscripts.GetFlowBalance(t, se, artistAcct.Address), 0.00000001)
+		assert.InDelta(t, sellerBalance+93.0, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.00000001)
+	})
+
+	t.Run("Should reject extra roles that replace the fee", func(t *testing.T) {
+		_, err := client.Script(`
+		import MetadataViews from 0xf8d6e0586b0a20c7
+		import FlowToken from 0x0ae53cb6e3f42a79
+		import Evergreen from 0x179b6b1cb6755e31
+		import SequelMarketplace from 0x179b6b1cb6755e31
+
+		access(all) fun main(seller: Address): SequelMarketplace.PaymentQuote {
+			return SequelMarketplace.quotePayments(
+				profile: Evergreen.Profile(id: "did:sequel:evergreen1", description: "", roles: []),
+				seller: seller,
+				sellerRole: "Owner",
+				sellerVaultPath: /public/flowTokenReceiver,
+				price: 100.0,
+				defaultReceiverPath: MetadataViews.getRoyaltyReceiverPublicPath(),
+				initialSale: false,
+				extraRoles: [
+					Evergreen.Role(
+						id: "Platform",
+						description: "",
+						initialSaleCommission: 0.0,
+						secondaryMarketCommission: 0.0,
+						address: seller,
+						receiverPath: nil
+					)
+				],
+				previousOwners: [],
+				paymentVaultType: Type<@FlowToken.Vault>()
+			)
+		}
+		`).
+			Argument(cadence.NewAddress(sellerAcct.Address)).
+			RunReturns(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Extra role conflicts with the platform fee")
+	})
+
+	t.Run("Should charge the fee even if the profile includes the same role", func(t *testing.T) {
+		metadata := SampleMetadata(1)
+		metadata.Asset = "did:sequel:asset-platform"
+		require.NoError(t, c.SealMaster(ctx, metadata, PrimaryOnlyEvergreenProfile(artistAcct.Address, platformAcct.Address)))
+
+		nftIDs, err := c.MintEdition(ctx, metadata.Asset, 1, sellerAcct.Address)
+		require.NoError(t, err)
+
+		listingID, err := c.ListToken(ctx, sellerAcctName, nftIDs[0], "100.0", flowToken, nil)
+		require.NoError(t, err)
+
+		platformBalance := testscripts.GetFlowBalance(t, se, platformAcct.Address)
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		_, err = c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, listingID, flowToken, nil)
+		require.NoError(t, err)
+
+		assert.InDelta(t, platformBalance+2.0, testscripts.GetFlowBalance(t, se, platformAcct.Address), 0.00000001)
+		assert.InDelta(t, sellerBalance+93.0, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.00000001)
+	})
+
+	t.Run("Should reject listings if commissions and the fee exceed the price", func(t *testing.T) {
+		metadata := SampleMetadata(1)
+		metadata.Asset = "did:sequel:asset-high-fee"
+		require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+		nftIDs, err := c.MintEdition(ctx, metadata.Asset, 1, sellerAcct.Address)
+		require.NoError(t, err)
+
+		highFee := *flowFee
+		highFee.SecondaryMarketCommission = evergreen.MustParseUFix64("0.96")
+		require.NoError(t, c.SetFee(ctx, &flowToken, &highFee))
+
+		_, err = c.ListToken(ctx, sellerAcctName, nftIDs[0], "100.0", flowToken, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Commissions and platform fee exceed the price")
+
+		require.NoError(t, c.SetFee(ctx, &flowToken, flowFee))
+
+		_, err = c.ListToken(ctx, sellerAcctName, nftIDs[0], "100.0", flowToken, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("Should be able to remove fees", func(t *testing.T) {
+		require.NoError(t, c.RemoveFee(ctx, &flowToken))
+
+		policy, err := c.GetFeePolicy(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, defaultFee, policy.Fee(flowVaultType))
+
+		require.NoError(t, c.RemoveFee(ctx, nil))
+
+		policy, err = c.GetFeePolicy(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, policy.Fee(flowVaultType))
+	})
+
+	t.Run("Only the admin account should be able to set fees", func(t *testing.T) {
+		err := iinft.NewClient(client, se, sellerAcctName).SetFee(ctx, nil, defaultFee)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Could not borrow a reference to the FeeAdmin")
+	})
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPool(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	adminAcct := client.Account(adminAccountName)
+	artistAcct := client.Account(platformAccountName)
+
+	recipientAcctName := user1AccountName
+	recipientAcct := client.Account(recipientAcctName)
+	testscripts.FundAccountWithFlow(t, se, recipientAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(recipientAcctName).Test(t).AssertSuccess()
+
+	metadata := SampleMetadata(20)
+	require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+	indexes, err := c.AddProposalKeys(ctx, adminAcct.Key.Index(), 4)
+	require.NoError(t, err)
+	require.Len(t, indexes, 4)
+
+	keys := make([]accounts.Key, len(indexes))
+	for i, index := range indexes {
+		keys[i], err = iinft.CloneKey(adminAcct.Key, index)
+		require.NoError(t, err)
+	}
+
+	t.Run("Should mint concurrently with pooled keys", func(t *testing.T) {
+		pool, err := iinft.NewKeyPool(client, adminAcct.Address, keys)
+		require.NoError(t, err)
+		assert.Equal(t, 4, pool.Size())
+
+		pc, err := c.WithKeyPool(pool)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		ids := make([][]uint64, 8)
+		errs := make([]error, 8)
+		for i := range ids {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ids[i], errs[i] = pc.MintEdition(ctx, metadata.Asset, 1, recipientAcct.Address)
+			}(i)
+		}
+		wg.Wait()
+
+		seen := map[uint64]bool{}
+		for i := range ids {
+			require.NoError(t, errs[i])
+			require.Len(t, ids[i], 1)
+			seen[ids[i][0]] = true
+		}
+		assert.Len(t, seen, 8)
+
+		checkDigitalArtCollectionLen(t, se, recipientAcct.Address.String(), 8)
+	})
+
+	t.Run("Should resync sequence numbers of keys used outside the pool", func(t *testing.T) {
+		pool, err := iinft.NewKeyPool(client, adminAcct.Address, keys[:1])
+		require.NoError(t, err)
+
+		pc, err := c.WithKeyPool(pool)
+		require.NoError(t, err)
+
+		_, err = pc.MintEdition(ctx, metadata.Asset, 1, recipientAcct.Address)
+		require.NoError(t, err)
+
+		// the same key signs a transaction without the pool
+		iinft.RegisterSigner(client, "admin-pooled-key", adminAcct.Address, keys[0])
+		_, err = iinft.NewClient(client, se, "admin-pooled-key").MintEdition(ctx, metadata.Asset, 1, recipientAcct.Address)
+		require.NoError(t, err)
+
+		_, err = pc.MintEdition(ctx, metadata.Asset, 1, recipientAcct.Address)
+		require.NoError(t, err)
+
+		checkDigitalArtCollectionLen(t, se, recipientAcct.Address.String(), 11)
+	})
+
+	t.Run("Should reject a pool of another account", func(t *testing.T) {
+		pool, err := iinft.NewKeyPool(client, recipientAcct.Address, keys)
+		require.NoError(t, err)
+
+		_, err = c.WithKeyPool(pool)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "doesn't match admin account address")
+	})
+
+	t.Run("Should refuse to manage transactions of a pooled client", func(t *testing.T) {
+		pool, err := iinft.NewKeyPool(client, adminAcct.Address, keys)
+		require.NoError(t, err)
+
+		pc, err := c.WithKeyPool(pool)
+		require.NoError(t, err)
+
+		_, err = iinft.NewTxManager(pc, iinft.NewMemoryTxStore(), iinft.TxManagerOptions{})
+		require.ErrorIs(t, err, iinft.ErrKeyPoolNotSupported)
+	})
+
+	t.Run("Should fail to create an empty pool", func(t *testing.T) {
+		_, err := iinft.NewKeyPool(client, adminAcct.Address, nil)
+		require.ErrorIs(t, err, iinft.ErrEmptyKeyPool)
+	})
+}
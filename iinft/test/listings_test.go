@@ -0,0 +1,376 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_StaleListings(t *testing.T) {
+	client, err := testscripts.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	artistAcct := client.Account(platformAccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName)
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	buyerAcctName := user2AccountName
+	buyerAcct := client.Account(buyerAcctName)
+	testscripts.FundAccountWithFlow(t, se, buyerAcct.Address, "1000.0")
+
+	recipientAcct := client.Account(user3AccountName)
+	testscripts.FundAccountWithFlow(t, se, recipientAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(user3AccountName).Test(t).AssertSuccess()
+
+	metadata := SampleMetadata(4)
+	require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+	nftIDs, err := c.MintEdition(ctx, metadata.Asset, 4, sellerAcct.Address)
+	require.NoError(t, err)
+	require.Len(t, nftIDs, 4)
+
+	expiry := time.Now().Add(5 * time.Second)
+	expiringID, err := c.ListTokenWithExpiry(ctx, sellerAcctName, nftIDs[0], "100.0", flowToken, nil, expiry)
+	require.NoError(t, err)
+
+	validID, err := c.ListToken(ctx, sellerAcctName, nftIDs[1], "100.0", flowToken, nil)
+	require.NoError(t, err)
+
+	ghostID, err := c.ListToken(ctx, sellerAcctName, nftIDs[2], "100.0", flowToken, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.Transfer(ctx, sellerAcctName, nftIDs[2], recipientAcct.Address))
+
+	soldID, err := c.ListToken(ctx, sellerAcctName, nftIDs[3], "100.0", flowToken, nil)
+	require.NoError(t, err)
+
+	t.Run("Should fail to list with expiry in the past", func(t *testing.T) {
+		_, err := c.ListTokenWithExpiry(ctx, sellerAcctName, nftIDs[1], "100.0", flowToken, nil, time.Now().Add(-time.Hour))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Expiry must be in the future")
+	})
+
+	t.Run("Should keep the expiry in the seller's account", func(t *testing.T) {
+		val, err := client.Script(`
+		import SequelMarketplace from 0x179b6b1cb6755e31
+
+		access(all) fun main(seller: Address, listingID: UInt64): Bool {
+			return getAccount(seller).capabilities.borrow<&SequelMarketplace.ListingExpiries>(SequelMarketplace.getListingExpiriesPublicPath())!
+				.getExpiry(listingID: listingID) != nil
+		}
+		`).
+			Argument(cadence.NewAddress(sellerAcct.Address)).
+			UInt64Argument(expiringID).
+			RunReturns(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewBool(true), val)
+	})
+
+	t.Run("Should find ghost listings", func(t *testing.T) {
+		stale, err := c.GetStaleListings(ctx, sellerAcct.Address, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []*iinft.StaleListing{
+			{ListingID: ghostID, Reason: iinft.StaleReasonGhost},
+		}, stale)
+	})
+
+	t.Run("Should be able to buy a listing before expiry", func(t *testing.T) {
+		_, err := c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, soldID, flowToken, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("Should fail to buy an expired listing", func(t *testing.T) {
+		testscripts.AdvanceBlockTime(t, client, expiry.Add(time.Second))
+
+		_, err := c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, expiringID, flowToken, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Listing expired")
+
+		checkTokenInDigitalArtCollection(t, se, sellerAcct.Address.String(), nftIDs[0])
+	})
+
+	t.Run("Should clean up stale listings in batches", func(t *testing.T) {
+		stale, err := c.GetStaleListings(ctx, sellerAcct.Address, 1)
+		require.NoError(t, err)
+		require.Len(t, stale, 2)
+
+		cleaned, err := c.CleanupStaleListings(ctx, sellerAcct.Address, iinft.CleanupOptions{BatchSize: 1})
+		require.NoError(t, err)
+		require.Len(t, cleaned, 2)
+
+		reasons := map[uint64]string{}
+		for _, ev := range cleaned {
+			assert.Equal(t, sellerAcct.Address, ev.StorefrontAddress)
+			reasons[ev.ListingID] = ev.Reason
+		}
+		assert.Equal(t, map[uint64]string{
+			expiringID: iinft.StaleReasonExpired,
+			ghostID:    iinft.StaleReasonGhost,
+		}, reasons)
+
+		stale, err = c.GetStaleListings(ctx, sellerAcct.Address, 0)
+		require.NoError(t, err)
+		assert.Empty(t, stale)
+
+		// the valid listing stays in the storefront and can be purchased
+		_, err = c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, validID, flowToken, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestClient_MultiCurrencyListing(t *testing.T) {
+	client, err := testscripts.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+	exampleToken, err := c.TokenContract("ExampleToken")
+	require.NoError(t, err)
+
+	artistAcct := client.Account(platformAccountName)
+	testscripts.FundAccountWithFlow(t, se, artistAcct.Address, "10.0")
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName, "ExampleToken")
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+	_ = se.NewTransaction("account_setup_example_ft").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	buyerAcctName := user2AccountName
+	buyerAcct := client.Account(buyerAcctName)
+	testscripts.FundAccountWithFlow(t, se, buyerAcct.Address, "1000.0")
+	_ = se.NewTransaction("account_setup_example_ft").SignProposeAndPayAs(buyerAcctName).Test(t).AssertSuccess()
+	testscripts.FundAccountWithExampleToken(t, se, buyerAcct.Address, "1000.0")
+
+	metadata := SampleMetadata(3)
+	require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+	nftIDs, err := c.MintEdition(ctx, metadata.Asset, 3, sellerAcct.Address)
+	require.NoError(t, err)
+	require.Len(t, nftIDs, 3)
+
+	prices := []iinft.PriceOption{
+		{Price: "100.0", Token: flowToken},
+		{Price: "50.0", Token: exampleToken},
+	}
+
+	t.Run("Should fail to list with duplicate currencies", func(t *testing.T) {
+		_, err := c.ListTokenWithPrices(ctx, sellerAcctName, nftIDs[0], []iinft.PriceOption{
+			{Price: "100.0", Token: flowToken},
+			{Price: "50.0", Token: flowToken},
+		}, nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Duplicate payment vault type")
+	})
+
+	t.Run("Should sell in one currency and remove other listings", func(t *testing.T) {
+		listed, err := c.ListTokenWithPrices(ctx, sellerAcctName, nftIDs[0], prices, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+
+		assert.Equal(t, "A.0ae53cb6e3f42a79.FlowToken.Vault", listed[0].PaymentVaultType)
+		assert.Equal(t, evergreen.MustParseUFix64("100.0"), listed[0].Price)
+		assert.Equal(t, "A.f8d6e0586b0a20c7.ExampleToken.Vault", listed[1].PaymentVaultType)
+		assert.Equal(t, evergreen.MustParseUFix64("50.0"), listed[1].Price)
+		assert.NotEqual(t, listed[0].ListingID, listed[1].ListingID)
+
+		sellerBalance := testscripts.GetExampleTokenBalance(t, se, sellerAcct.Address)
+
+		nftID, err := c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, listed[1].ListingID, exampleToken, nil)
+		require.NoError(t, err)
+		assert.Equal(t, nftIDs[0], nftID)
+
+		assert.InDelta(t, sellerBalance+47.5, testscripts.GetExampleTokenBalance(t, se, sellerAcct.Address), 0.00000001)
+
+		_, err = c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, listed[0].ListingID, flowToken, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "No Offer with that ID in Storefront")
+
+		stale, err := c.GetStaleListings(ctx, sellerAcct.Address, 0)
+		require.NoError(t, err)
+		assert.Empty(t, stale)
+	})
+
+	t.Run("Should withdraw listings in all currencies", func(t *testing.T) {
+		listed, err := c.ListTokenWithPrices(ctx, sellerAcctName, nftIDs[1], prices, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+
+		require.NoError(t, c.WithdrawListing(ctx, sellerAcctName, listed[1].ListingID))
+
+		_, err = c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, listed[0].ListingID, flowToken, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "No Offer with that ID in Storefront")
+
+		checkTokenInDigitalArtCollection(t, se, sellerAcct.Address.String(), nftIDs[1])
+	})
+
+	t.Run("Should remember other listings if they can't be removed", func(t *testing.T) {
+		listed, err := c.ListTokenWithPrices(ctx, sellerAcctName, nftIDs[2], prices, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+
+		// revoke the cleanup capability, so that the other listing stays in the storefront
+		_ = client.Transaction(`
+		import NFTStorefront from 0xf8d6e0586b0a20c7
+
+		transaction {
+			prepare(acct: auth(GetStorageCapabilityController) &Account) {
+				for controller in acct.capabilities.storage.getControllers(forPath: NFTStorefront.StorefrontStoragePath) {
+					if controller.borrowType == Type<auth(NFTStorefront.RemoveListing) &NFTStorefront.Storefront>() {
+						controller.delete()
+					}
+				}
+			}
+		}`).
+			SignProposeAndPayAs(sellerAcctName).
+			Test(t).
+			AssertSuccess()
+
+		_, err = c.BuyToken(ctx, buyerAcctName, sellerAcct.Address, listed[0].ListingID, flowToken, nil)
+		require.NoError(t, err)
+
+		val, err := client.Script(`
+		import SequelMarketplace from 0x179b6b1cb6755e31
+
+		access(all) fun main(listingID: UInt64): Int {
+			return SequelMarketplace.getListingGroup(listingID: listingID).length
+		}
+		`).
+			UInt64Argument(listed[1].ListingID).
+			RunReturns(ctx)
+		require.NoError(t, err)
+		// the listing is only forgotten once it's removed from the storefront
+		assert.Equal(t, cadence.NewInt(2), val)
+
+		stale, err := c.GetStaleListings(ctx, sellerAcct.Address, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []*iinft.StaleListing{
+			{ListingID: listed[1].ListingID, Reason: iinft.StaleReasonGhost},
+		}, stale)
+	})
+}
+
+func TestClient_QuoteListing(t *testing.T) {
+	client, err := testscripts.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	artistAcct := client.Account(platformAccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName)
+
+	// the agent doesn't have royalty receivers
+	agentAcct := client.Account(user3AccountName)
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	t.Run("Should report skipped royalties", func(t *testing.T) {
+		metadata := SampleMetadata(1)
+		profile := BasicEvergreenProfile(artistAcct.Address)
+		profile.Roles = append(profile.Roles, &evergreen.Role{
+			ID:                        "Agent",
+			InitialSaleCommission:     evergreen.MustParseUFix64("0.0"),
+			SecondaryMarketCommission: evergreen.MustParseUFix64("0.1"),
+			Address:                   agentAcct.Address,
+		})
+		require.NoError(t, c.SealMaster(ctx, metadata, profile))
+
+		nftIDs, err := c.MintEdition(ctx, metadata.Asset, 1, sellerAcct.Address)
+		require.NoError(t, err)
+
+		quote, err := c.QuoteListing(ctx, sellerAcct.Address, nftIDs[0], "100.0", flowToken)
+		require.NoError(t, err)
+
+		assert.Equal(t, &iinft.ListingQuote{
+			Price:          evergreen.MustParseUFix64("100.0"),
+			SellerProceeds: evergreen.MustParseUFix64("95.0"),
+			Royalties: []*evergreen.Payment{
+				{
+					Role:     evergreen.RoleArtist,
+					Receiver: artistAcct.Address,
+					Amount:   evergreen.MustParseUFix64("5.0"),
+					Rate:     evergreen.MustParseUFix64("0.05"),
+				},
+			},
+			SkippedRoyalties: []*evergreen.Payment{
+				{
+					Role:     "Agent",
+					Receiver: agentAcct.Address,
+					Amount:   evergreen.MustParseUFix64("10.0"),
+					Rate:     evergreen.MustParseUFix64("0.1"),
+				},
+			},
+		}, quote)
+	})
+
+	t.Run("Should report zero proceeds if commissions reach 100%", func(t *testing.T) {
+		metadata := SampleMetadata(1)
+		metadata.Asset = "did:sequel:asset-full-commission"
+		profile := BasicEvergreenProfile(artistAcct.Address)
+		profile.Roles[0].SecondaryMarketCommission = evergreen.MustParseUFix64("1.0")
+		require.NoError(t, c.SealMaster(ctx, metadata, profile))
+
+		nftIDs, err := c.MintEdition(ctx, metadata.Asset, 1, sellerAcct.Address)
+		require.NoError(t, err)
+
+		quote, err := c.QuoteListing(ctx, sellerAcct.Address, nftIDs[0], "100.0", flowToken)
+		require.NoError(t, err)
+
+		assert.Equal(t, evergreen.UFix64(0), quote.SellerProceeds)
+		require.Len(t, quote.Royalties, 1)
+		assert.Equal(t, evergreen.MustParseUFix64("100.0"), quote.Royalties[0].Amount)
+		assert.Empty(t, quote.SkippedRoyalties)
+	})
+
+	t.Run("Should fail if the token isn't in the seller's collection", func(t *testing.T) {
+		_, err := c.QuoteListing(ctx, sellerAcct.Address, 999, "100.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Token not found in the seller's collection")
+	})
+}
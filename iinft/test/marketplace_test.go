@@ -367,14 +367,14 @@ func TestMarketplace_payForMintedTokens(t *testing.T) {
 		Roles: []*evergreen.Role{
 			{
 				ID:                        "Artist",
-				InitialSaleCommission:     0.8,
-				SecondaryMarketCommission: 0.0,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.0"),
 				Address:                   artistAcct.Address,
 			},
 			{
 				ID:                        "Role1",
-				InitialSaleCommission:     0.2,
-				SecondaryMarketCommission: 0.0,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.2"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.0"),
 				Address:                   roleOneAcct.Address,
 			},
 		},
@@ -673,14 +673,14 @@ func TestMarketplace_buildPayments(t *testing.T) {
 		Roles: []*evergreen.Role{
 			{
 				ID:                        "Role1",
-				InitialSaleCommission:     0.8,
-				SecondaryMarketCommission: 0.05,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
 				Address:                   roleOneAcct.Address,
 			},
 			{
 				ID:                        "Role2",
-				InitialSaleCommission:     0.2,
-				SecondaryMarketCommission: 0.025,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.2"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.025"),
 				Address:                   roleTwoAcct.Address,
 			},
 		},
@@ -774,10 +774,12 @@ access(all) fun main(profile: Evergreen.Profile, seller: Address) {
 
 	t.Run("Should fail if any of the rates are out of range", func(t *testing.T) {
 		profile := BasicEvergreenProfile(roleOneAcct.Address)
-		profile.Roles[0].InitialSaleCommission = 1.25
+		profile.Roles[0].InitialSaleCommission = evergreen.MustParseUFix64("1.25")
 
-		profileVal, err := evergreen.ProfileToCadence(profile, evergreenAddr)
-		require.NoError(t, err)
+		_, err = evergreen.ProfileToCadence(profile, evergreenAddr)
+		require.Error(t, err)
+
+		profileVal := testscripts.ProfileToCadenceUnchecked(t, profile, evergreenAddr)
 
 		_, err = client.Script(`
 import Evergreen from 0x179b6b1cb6755e31
@@ -802,24 +804,28 @@ access(all) fun main(profile: Evergreen.Profile, seller: Address) {
 	})
 
 	t.Run("Should fail if sum of rates is greater than 1.0", func(t *testing.T) {
-		profile, err := evergreen.ProfileToCadence(&evergreen.Profile{
+		sourceProfile := &evergreen.Profile{
 			ID: "did:sequel:evergreen3",
 			Roles: []*evergreen.Role{
 				{
 					ID:                        "Role1",
-					InitialSaleCommission:     0.8,
-					SecondaryMarketCommission: 0.0,
+					InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+					SecondaryMarketCommission: evergreen.MustParseUFix64("0.0"),
 					Address:                   roleOneAcct.Address,
 				},
 				{
 					ID:                        "Role2",
-					InitialSaleCommission:     0.8,
-					SecondaryMarketCommission: 0.0,
+					InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+					SecondaryMarketCommission: evergreen.MustParseUFix64("0.0"),
 					Address:                   roleTwoAcct.Address,
 				},
 			},
-		}, evergreenAddr)
-		require.NoError(t, err)
+		}
+
+		_, err = evergreen.ProfileToCadence(sourceProfile, evergreenAddr)
+		require.Error(t, err)
+
+		profile := testscripts.ProfileToCadenceUnchecked(t, sourceProfile, evergreenAddr)
 
 		_, err = client.Script(`
 import Evergreen from 0x179b6b1cb6755e31
@@ -849,14 +855,14 @@ access(all) fun main(profile: Evergreen.Profile, seller: Address) {
 			Roles: []*evergreen.Role{
 				{
 					ID:                        "Role1",
-					InitialSaleCommission:     0.8,
-					SecondaryMarketCommission: 0.05,
+					InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+					SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
 					Address:                   roleOneAcct.Address,
 				},
 				{
 					ID:                        "Role2",
-					InitialSaleCommission:     0.2,
-					SecondaryMarketCommission: 0.0,
+					InitialSaleCommission:     evergreen.MustParseUFix64("0.2"),
+					SecondaryMarketCommission: evergreen.MustParseUFix64("0.0"),
 					Address:                   roleTwoAcct.Address,
 				},
 			},
@@ -949,8 +955,8 @@ access(all) fun main(profile: Evergreen.Profile, seller: Address) {
 			Roles: []*evergreen.Role{
 				{
 					ID:                        "Role1",
-					InitialSaleCommission:     1.0,
-					SecondaryMarketCommission: 0.05,
+					InitialSaleCommission:     evergreen.MustParseUFix64("1.0"),
+					SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
 					Address:                   roleOneAcct.Address,
 				},
 			},
@@ -1024,3 +1030,238 @@ access(all) fun main(profile: Evergreen.Profile, seller: Address, extra1: Addres
 		require.NoError(t, err)
 	})
 }
+
+func TestMarketplace_buildPayments_Go(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	evergreenAddr := flow.HexToAddress(se.WellKnownAddresses()["Evergreen"])
+
+	// only user1 has a royalty receiver, so payments to user2 will fall back to the seller
+
+	testscripts.SetUpRoyaltyReceivers(t, se, user1AccountName, adminAccountName)
+
+	roleOneAcct := client.Account(user1AccountName)
+	roleTwoAcct := client.Account(user2AccountName)
+	sellerAcct := client.Account(user3AccountName)
+
+	const (
+		royaltyReceiverPath = "/public/GenericFTReceiver"
+		sellerVaultPath     = "/public/flowTokenReceiver"
+	)
+
+	hasReceiver := func(_ string, address flow.Address, receiverPath string) bool {
+		if receiverPath == "" {
+			if address == sellerAcct.Address {
+				receiverPath = sellerVaultPath
+			} else {
+				receiverPath = royaltyReceiverPath
+			}
+		}
+
+		path, err := splash.StringToPath(receiverPath)
+		require.NoError(t, err)
+
+		val, err := client.Script(`
+import FungibleToken from 0xee82856bf20e2aa6
+
+access(all) fun main(address: Address, path: PublicPath): Bool {
+	return getAccount(address).capabilities.get<&{FungibleToken.Receiver}>(path).check()
+}`).
+			Argument(cadence.NewAddress(address)).
+			Argument(path).
+			RunReturns(ctx)
+		require.NoError(t, err)
+
+		return bool(val.(cadence.Bool))
+	}
+
+	onChainPayments := func(profile *evergreen.Profile, price evergreen.UFix64, initialSale bool, extraRoles []*evergreen.Role, previousOwners []flow.Address) ([]*evergreen.Payment, error) {
+		profileVal := testscripts.ProfileToCadenceUnchecked(t, profile, evergreenAddr)
+
+		extraRoleVals := make([]cadence.Value, len(extraRoles))
+		for i, role := range extraRoles {
+			extraRoleVals[i], err = evergreen.RoleToCadence(role, evergreenAddr)
+			require.NoError(t, err)
+		}
+
+		previousOwnerVals := make([]cadence.Value, len(previousOwners))
+		for i, addr := range previousOwners {
+			previousOwnerVals[i] = cadence.NewAddress(addr)
+		}
+
+		path, err := splash.StringToPath(sellerVaultPath)
+		require.NoError(t, err)
+
+		val, err := se.NewScript("marketplace_build_payments").
+			Argument(profileVal).
+			Argument(cadence.NewAddress(sellerAcct.Address)).
+			StringArgument(evergreen.RoleOwner).
+			Argument(path).
+			Argument(price.Cadence()).
+			BooleanArgument(initialSale).
+			Argument(cadence.NewArray(extraRoleVals)).
+			Argument(cadence.NewArray(previousOwnerVals)).
+			RunReturns(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var res []*evergreen.Payment
+		for _, paymentVal := range val.(cadence.Array).Values {
+			payment, err := iinft.PaymentFromCadence(paymentVal)
+			require.NoError(t, err)
+			res = append(res, payment)
+		}
+
+		return res, nil
+	}
+
+	role := func(id string, initialSaleCommission, secondaryMarketCommission string, address flow.Address) *evergreen.Role {
+		return &evergreen.Role{
+			ID:                        id,
+			InitialSaleCommission:     evergreen.MustParseUFix64(initialSaleCommission),
+			SecondaryMarketCommission: evergreen.MustParseUFix64(secondaryMarketCommission),
+			Address:                   address,
+		}
+	}
+
+	flowReceiverRole := role("Role3", "0.1", "0.1", roleTwoAcct.Address)
+	flowReceiverRole.ReceiverPath = sellerVaultPath
+
+	collectorRole := role(evergreen.RoleCollector, "0.1", "0.1", flow.EmptyAddress)
+
+	testCases := []struct {
+		name           string
+		profile        *evergreen.Profile
+		price          string
+		extraRoles     []*evergreen.Role
+		previousOwners []flow.Address
+		fail           bool
+	}{
+		{
+			name: "Two roles",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "0.8", "0.05", roleOneAcct.Address),
+				role("Role2", "0.2", "0.025", roleOneAcct.Address),
+			}},
+			price: "100.0",
+		},
+		{
+			name: "Truncated amounts",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "0.3333", "0.0333", roleOneAcct.Address),
+				role("Role2", "0.3333", "0.0777", roleOneAcct.Address),
+			}},
+			price: "10.00000007",
+		},
+		{
+			name: "Missing receiver",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "0.5", "0.05", roleOneAcct.Address),
+				role("Role2", "0.5", "0.05", roleTwoAcct.Address),
+			}},
+			price: "33.3",
+		},
+		{
+			name: "Explicit receiver path",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "0.5", "0.05", roleOneAcct.Address),
+				flowReceiverRole,
+			}},
+			price: "1.0",
+		},
+		{
+			name:    "Extra roles",
+			profile: BasicEvergreenProfile(roleOneAcct.Address),
+			price:   "0.00000099",
+			extraRoles: []*evergreen.Role{
+				role("Extra1", "0.0", "0.02", roleOneAcct.Address),
+				role("Extra2", "0.0", "0.04", roleTwoAcct.Address),
+			},
+		},
+		{
+			name:    "No roles",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{}},
+			price:   "100.0",
+		},
+		{
+			name: "Collectors",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "0.5", "0.05", roleOneAcct.Address),
+				collectorRole,
+			}},
+			price:          "10.0",
+			previousOwners: []flow.Address{roleTwoAcct.Address, roleOneAcct.Address, sellerAcct.Address},
+		},
+		{
+			name: "Collectors with truncated shares",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				collectorRole,
+			}},
+			price: "1.0",
+			previousOwners: []flow.Address{
+				roleOneAcct.Address, sellerAcct.Address, flow.HexToAddress("0x01"), flow.HexToAddress("0x02"),
+				roleOneAcct.Address,
+			},
+		},
+		{
+			name: "No collectors",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "0.5", "0.05", roleOneAcct.Address),
+				collectorRole,
+			}},
+			price:          "10.0",
+			previousOwners: []flow.Address{sellerAcct.Address},
+		},
+		{
+			name: "Rate out of range",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "1.25", "1.25", roleOneAcct.Address),
+			}},
+			price: "100.0",
+			fail:  true,
+		},
+		{
+			name: "Sum of rates greater than 1.0",
+			profile: &evergreen.Profile{ID: "did:sequel:evergreen3", Roles: []*evergreen.Role{
+				role("Role1", "0.8", "0.8", roleOneAcct.Address),
+				role("Role2", "0.8", "0.8", roleOneAcct.Address),
+			}},
+			price: "100.0",
+			fail:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, initialSale := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s (initialSale=%v)", tc.name, initialSale), func(t *testing.T) {
+				price := evergreen.MustParseUFix64(tc.price)
+
+				expected, err := onChainPayments(tc.profile, price, initialSale, tc.extraRoles, tc.previousOwners)
+				if tc.fail {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				instructions, err := evergreen.BuildPaymentsWithCollectors(tc.profile, sellerAcct.Address, evergreen.RoleOwner, price,
+					initialSale, tc.extraRoles, nil, tc.previousOwners, hasReceiver)
+				if tc.fail {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Equal(t, expected, instructions.Payments)
+			})
+		}
+	}
+}
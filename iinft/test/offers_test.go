@@ -0,0 +1,172 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Offers(t *testing.T) {
+	client, err := testscripts.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	artistAcct := client.Account(platformAccountName)
+	testscripts.SetUpRoyaltyReceivers(t, se, platformAccountName, adminAccountName)
+
+	sellerAcctName := user1AccountName
+	sellerAcct := client.Account(sellerAcctName)
+	testscripts.FundAccountWithFlow(t, se, sellerAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(sellerAcctName).Test(t).AssertSuccess()
+
+	buyerAcctName := user2AccountName
+	buyerAcct := client.Account(buyerAcctName)
+	testscripts.FundAccountWithFlow(t, se, buyerAcct.Address, "1000.0")
+
+	metadata := SampleMetadata(2)
+	require.NoError(t, c.SealMaster(ctx, metadata, BasicEvergreenProfile(artistAcct.Address)))
+
+	nftIDs, err := c.MintEdition(ctx, metadata.Asset, 2, sellerAcct.Address)
+	require.NoError(t, err)
+	require.Len(t, nftIDs, 2)
+
+	t.Run("Should be able to make and cancel an offer", func(t *testing.T) {
+		buyerBalance := testscripts.GetFlowBalance(t, se, buyerAcct.Address)
+
+		offerID, err := c.MakeOffer(ctx, buyerAcctName, nftIDs[0], "10.0", flowToken, nil)
+		require.NoError(t, err)
+
+		assert.InDelta(t, buyerBalance-10.0, testscripts.GetFlowBalance(t, se, buyerAcct.Address), 0.001)
+
+		offers, err := c.GetOffers(ctx, buyerAcct.Address)
+		require.NoError(t, err)
+		require.Len(t, offers, 1)
+		assert.Equal(t, offerID, offers[0].OfferID)
+		assert.Equal(t, buyerAcct.Address, offers[0].BuyerAddress)
+		assert.Equal(t, "A.179b6b1cb6755e31.DigitalArt.NFT", offers[0].NFTType)
+		require.NotNil(t, offers[0].NFTID)
+		assert.Equal(t, nftIDs[0], *offers[0].NFTID)
+		assert.Nil(t, offers[0].Asset)
+		assert.Equal(t, "A.0ae53cb6e3f42a79.FlowToken.Vault", offers[0].PaymentVaultType)
+		assert.Equal(t, evergreen.MustParseUFix64("10.0"), offers[0].Price)
+		assert.Nil(t, offers[0].Expiry)
+
+		require.NoError(t, c.CancelOffer(ctx, buyerAcctName, offerID))
+
+		assert.InDelta(t, buyerBalance, testscripts.GetFlowBalance(t, se, buyerAcct.Address), 0.001)
+
+		offers, err = c.GetOffers(ctx, buyerAcct.Address)
+		require.NoError(t, err)
+		assert.Empty(t, offers)
+	})
+
+	t.Run("Should be able to accept an offer for a token", func(t *testing.T) {
+		offerID, err := c.MakeOffer(ctx, buyerAcctName, nftIDs[0], "100.0", flowToken, nil)
+		require.NoError(t, err)
+
+		_, err = c.AcceptOffer(ctx, sellerAcctName, buyerAcct.Address, offerID, nftIDs[1], "100.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "NFT ID mismatch")
+
+		_, err = c.AcceptOffer(ctx, sellerAcctName, buyerAcct.Address, offerID, nftIDs[0], "200.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Price mismatch")
+
+		artistBalance := testscripts.GetFlowBalance(t, se, artistAcct.Address)
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		ev, err := c.AcceptOffer(ctx, sellerAcctName, buyerAcct.Address, offerID, nftIDs[0], "100.0", flowToken)
+		require.NoError(t, err)
+
+		assert.Equal(t, offerID, ev.OfferID)
+		assert.Equal(t, nftIDs[0], ev.NFTID)
+		assert.Equal(t, metadata.Asset, ev.Asset)
+		assert.Equal(t, sellerAcct.Address, ev.SellerAddress)
+		assert.Equal(t, []*evergreen.Payment{
+			{
+				Role:     evergreen.RoleArtist,
+				Receiver: artistAcct.Address,
+				Amount:   evergreen.MustParseUFix64("5.0"),
+				Rate:     evergreen.MustParseUFix64("0.05"),
+			},
+			{
+				Role:     evergreen.RoleOwner,
+				Receiver: sellerAcct.Address,
+				Amount:   evergreen.MustParseUFix64("95.0"),
+				Rate:     evergreen.MustParseUFix64("0.95"),
+			},
+		}, ev.Payments)
+
+		assert.InDelta(t, artistBalance+5.0, testscripts.GetFlowBalance(t, se, artistAcct.Address), 0.00000001)
+		assert.InDelta(t, sellerBalance+95.0, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.001)
+
+		checkTokenInDigitalArtCollection(t, se, buyerAcct.Address.String(), nftIDs[0])
+	})
+
+	t.Run("Should be able to accept an offer for any edition of an asset", func(t *testing.T) {
+		offerID, err := c.MakeAssetOffer(ctx, buyerAcctName, metadata.Asset, "50.0", flowToken, nil)
+		require.NoError(t, err)
+
+		offers, err := c.GetOffers(ctx, buyerAcct.Address)
+		require.NoError(t, err)
+		require.Len(t, offers, 1)
+		assert.Nil(t, offers[0].NFTID)
+		require.NotNil(t, offers[0].Asset)
+		assert.Equal(t, metadata.Asset, *offers[0].Asset)
+
+		sellerBalance := testscripts.GetFlowBalance(t, se, sellerAcct.Address)
+
+		ev, err := c.AcceptOffer(ctx, sellerAcctName, buyerAcct.Address, offerID, nftIDs[1], "50.0", flowToken)
+		require.NoError(t, err)
+		assert.Equal(t, nftIDs[1], ev.NFTID)
+
+		assert.InDelta(t, sellerBalance+47.5, testscripts.GetFlowBalance(t, se, sellerAcct.Address), 0.001)
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 2)
+		checkDigitalArtCollectionLen(t, se, sellerAcct.Address.String(), 0)
+
+		err = c.CancelOffer(ctx, buyerAcctName, offerID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Offer not found")
+	})
+
+	t.Run("Should not accept an expired offer", func(t *testing.T) {
+		nftID := nftIDs[1]
+		require.NoError(t, c.Transfer(ctx, buyerAcctName, nftID, sellerAcct.Address))
+
+		expiry := time.Now().Add(time.Hour)
+		offerID, err := c.MakeOffer(ctx, buyerAcctName, nftID, "20.0", flowToken, &expiry)
+		require.NoError(t, err)
+
+		offers, err := c.GetOffers(ctx, buyerAcct.Address)
+		require.NoError(t, err)
+		require.Len(t, offers, 1)
+		require.NotNil(t, offers[0].Expiry)
+		assert.Equal(t, iinft.TimeToUFix64(expiry), iinft.TimeToUFix64(*offers[0].Expiry))
+
+		testscripts.AdvanceBlockTime(t, client, expiry.Add(time.Second))
+
+		_, err = c.AcceptOffer(ctx, sellerAcctName, buyerAcct.Address, offerID, nftID, "20.0", flowToken)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Offer expired")
+
+		require.NoError(t, c.CancelOffer(ctx, buyerAcctName, offerID))
+	})
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterSigner(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	t.Run("Should sign transactions with a remote signer", func(t *testing.T) {
+		acct := client.Account(user1AccountName)
+		testscripts.FundAccountWithFlow(t, se, acct.Address, "10.0")
+
+		privateKey, err := acct.Key.PrivateKey()
+		require.NoError(t, err)
+		localSigner, err := crypto.NewInMemorySigner(*privateKey, acct.Key.HashAlgo())
+		require.NoError(t, err)
+
+		srv := httptest.NewServer(iinft.NewRemoteSignerHandler(localSigner))
+		defer srv.Close()
+
+		key := iinft.NewRemoteKey(srv.URL, (*privateKey).PublicKey(), iinft.KeyOptions{
+			Index:    acct.Key.Index(),
+			SigAlgo:  acct.Key.SigAlgo(),
+			HashAlgo: acct.Key.HashAlgo(),
+		}, nil)
+		iinft.RegisterSigner(client, "remote-user1", acct.Address, key)
+
+		_ = se.NewTransaction("account_setup").
+			SignAndProposeAs("remote-user1").
+			PayAs(adminAccountName).
+			Test(t).
+			AssertSuccess()
+
+		checkDigitalArtCollectionLen(t, se, acct.Address.String(), 0)
+	})
+
+	t.Run("Should sign transactions with a file key", func(t *testing.T) {
+		acct := client.Account(user2AccountName)
+		testscripts.FundAccountWithFlow(t, se, acct.Address, "10.0")
+
+		privateKey, err := acct.Key.PrivateKey()
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "user2.pkey")
+		require.NoError(t, os.WriteFile(path, []byte((*privateKey).String()), 0o600))
+
+		key, err := iinft.NewFileKey(path, iinft.KeyOptions{Index: acct.Key.Index()})
+		require.NoError(t, err)
+		iinft.RegisterSigner(client, "file-user2", acct.Address, key)
+
+		_ = se.NewTransaction("account_setup").
+			SignProposeAndPayAs("file-user2").
+			Test(t).
+			AssertSuccess()
+
+		checkDigitalArtCollectionLen(t, se, acct.Address.String(), 0)
+	})
+}
@@ -0,0 +1,165 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	platformAcct := client.Account(platformAccountName)
+
+	artistAcctName := user1AccountName
+	artistAcct := client.Account(artistAcctName)
+	testscripts.FundAccountWithFlow(t, se, artistAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(artistAcctName).Test(t).AssertSuccess()
+	testscripts.SetUpRoyaltyReceivers(t, se, artistAcctName, artistAcctName)
+
+	buyerAcctName := user2AccountName
+	buyerAcct := client.Account(buyerAcctName)
+	testscripts.FundAccountWithFlow(t, se, buyerAcct.Address, "1000.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(buyerAcctName).Test(t).AssertSuccess()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+	flowVaultType := fmt.Sprintf("A.%s.FlowToken.Vault", flowToken.Address.Hex())
+
+	metadata := SampleMetadata(200)
+	require.NoError(t, c.SealMaster(ctx, metadata, PrimaryOnlyEvergreenProfile(artistAcct.Address, platformAcct.Address)))
+
+	t.Run("Should simulate a transaction without changing the state", func(t *testing.T) {
+		buyerBalance := testscripts.GetFlowBalance(t, se, buyerAcct.Address)
+
+		res, err := c.DryRunMintOnDemand(ctx, buyerAcctName, metadata.Asset, 2, "10.0", flowToken, 1, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+
+		assert.NotZero(t, res.ComputationUsed)
+		assert.Less(t, res.ComputationUsed, res.ComputationLimit)
+
+		minted, err := c.Events().MintedEvents(res.Events)
+		require.NoError(t, err)
+		assert.Len(t, minted, 2)
+
+		var buyerDelta, artistDelta cadence.Fix64
+		for _, change := range res.BalanceChanges {
+			require.Equal(t, flowVaultType, change.VaultType)
+			switch change.Address {
+			case buyerAcct.Address:
+				buyerDelta = change.Delta
+			case artistAcct.Address:
+				artistDelta = change.Delta
+			}
+		}
+		// the buyer pays for the editions, while the admin account pays the transaction fees
+		assert.Equal(t, "-20.00000000", buyerDelta.String())
+		assert.Positive(t, int64(artistDelta))
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 0)
+		assert.Equal(t, buyerBalance, testscripts.GetFlowBalance(t, se, buyerAcct.Address))
+
+		// the state is rolled back, so the same editions can be minted again
+		ids, err := c.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 2, "10.0", flowToken, 1, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{minted[0].ID, minted[1].ID}, ids)
+	})
+
+	t.Run("Should report transaction errors", func(t *testing.T) {
+		res, err := c.DryRunMintOnDemand(ctx, buyerAcctName, metadata.Asset, 1000, "0.1", flowToken, 0, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		require.Error(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "too many editions requested")
+		assert.NotZero(t, res.ComputationUsed)
+	})
+
+	t.Run("Should estimate chunk sizes", func(t *testing.T) {
+		const limit = 2000
+
+		editions, err := c.EstimateMintOnDemandEditions(ctx, buyerAcctName, metadata.Asset, "1.0", flowToken, iinft.MintOnDemandParameters{}, limit)
+		require.NoError(t, err)
+		assert.Greater(t, editions, 1)
+
+		res, err := c.DryRunMintOnDemand(ctx, buyerAcctName, metadata.Asset, uint64(editions), "1.0", flowToken, 0, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		assert.LessOrEqual(t, res.ComputationUsed, uint64(limit))
+
+		chunkSize, err := c.EstimateMintBatchChunkSize(ctx, &iinft.BatchMintItem{MasterID: metadata.Asset, Recipient: buyerAcct.Address}, limit)
+		require.NoError(t, err)
+		assert.Greater(t, chunkSize, 1)
+
+		// a full chunk must fit into the limit
+		items := make([]*iinft.BatchMintItem, chunkSize)
+		for i := range items {
+			items[i] = &iinft.BatchMintItem{MasterID: metadata.Asset, Recipient: buyerAcct.Address}
+		}
+		results := c.MintBatch(ctx, items, iinft.BatchMintOptions{ChunkSize: chunkSize, GasLimit: limit})
+		require.Len(t, results, chunkSize)
+		for _, r := range results {
+			require.NoError(t, r.Err)
+			assert.Equal(t, results[0].TransactionID, r.TransactionID)
+		}
+	})
+
+	t.Run("Should estimate list chunk sizes", func(t *testing.T) {
+		const limit = 2000
+
+		minted := c.MintBatch(ctx, []*iinft.BatchMintItem{
+			{MasterID: metadata.Asset, Recipient: buyerAcct.Address},
+		}, iinft.BatchMintOptions{})
+		require.NoError(t, minted[0].Err)
+
+		chunkSize, err := c.EstimateListBatchChunkSize(ctx, buyerAcctName, &iinft.BatchListItem{
+			TokenID: minted[0].NFTID,
+			Price:   "10.0",
+			Token:   flowToken,
+		}, limit)
+		require.NoError(t, err)
+		assert.Greater(t, chunkSize, 1)
+
+		// the dry runs didn't list the token
+		listingIDs, err := se.NewScript("marketplace_get_listing_ids").
+			Argument(cadence.NewAddress(buyerAcct.Address)).
+			RunReturns(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, listingIDs.(cadence.Array).Values)
+
+		mintItems := make([]*iinft.BatchMintItem, chunkSize-1)
+		for i := range mintItems {
+			mintItems[i] = &iinft.BatchMintItem{MasterID: metadata.Asset, Recipient: buyerAcct.Address}
+		}
+		minted = append(minted, c.MintBatch(ctx, mintItems, iinft.BatchMintOptions{})...)
+
+		// a full chunk must fit into the limit
+		items := make([]*iinft.BatchListItem, chunkSize)
+		for i := range items {
+			require.NoError(t, minted[i].Err)
+			items[i] = &iinft.BatchListItem{TokenID: minted[i].NFTID, Price: "10.0", Token: flowToken}
+		}
+		results := c.ListBatch(ctx, buyerAcctName, items, iinft.BatchListOptions{ChunkSize: chunkSize, GasLimit: limit})
+		require.Len(t, results, chunkSize)
+		for _, r := range results {
+			require.NoError(t, r.Err)
+			assert.Equal(t, results[0].TransactionID, r.TransactionID)
+		}
+	})
+}
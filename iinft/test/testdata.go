@@ -29,8 +29,8 @@ func BasicEvergreenProfile(artist flow.Address) *evergreen.Profile {
 		Roles: []*evergreen.Role{
 			{
 				ID:                        evergreen.RoleArtist,
-				InitialSaleCommission:     1.0,
-				SecondaryMarketCommission: 0.05,
+				InitialSaleCommission:     evergreen.MustParseUFix64("1.0"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
 				Address:                   artist,
 			},
 		},
@@ -43,14 +43,14 @@ func PrimaryOnlyEvergreenProfile(artist, platform flow.Address) *evergreen.Profi
 		Roles: []*evergreen.Role{
 			{
 				ID:                        evergreen.RoleArtist,
-				InitialSaleCommission:     0.8,
-				SecondaryMarketCommission: 0.05,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.8"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.05"),
 				Address:                   artist,
 			},
 			{
 				ID:                        evergreen.RolePlatform,
-				InitialSaleCommission:     0.2,
-				SecondaryMarketCommission: 0.0,
+				InitialSaleCommission:     evergreen.MustParseUFix64("0.2"),
+				SecondaryMarketCommission: evergreen.MustParseUFix64("0.0"),
 				Address:                   platform,
 			},
 		},
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/piprate/sequel-flow-contracts/iinft"
+	"github.com/piprate/sequel-flow-contracts/iinft/testscripts"
+	"github.com/piprate/splash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxManager_MintOnDemand(t *testing.T) {
+	client, err := splash.NewInMemoryTestConnector("../..", true)
+	require.NoError(t, err)
+
+	testscripts.ConfigureInMemoryEmulator(t, client, "1000.0")
+
+	se, err := iinft.NewTemplateEngine(client)
+	require.NoError(t, err)
+
+	c := iinft.NewClient(client, se, adminAccountName)
+
+	ctx := context.Background()
+
+	platformAcct := client.Account(platformAccountName)
+
+	artistAcctName := user1AccountName
+	artistAcct := client.Account(artistAcctName)
+	testscripts.FundAccountWithFlow(t, se, artistAcct.Address, "10.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(artistAcctName).Test(t).AssertSuccess()
+	testscripts.SetUpRoyaltyReceivers(t, se, artistAcctName, artistAcctName)
+
+	buyerAcctName := user2AccountName
+	buyerAcct := client.Account(buyerAcctName)
+	testscripts.FundAccountWithFlow(t, se, buyerAcct.Address, "1000.0")
+	_ = se.NewTransaction("account_setup").SignProposeAndPayAs(buyerAcctName).Test(t).AssertSuccess()
+
+	flowToken, err := c.TokenContract("FlowToken")
+	require.NoError(t, err)
+
+	metadata := SampleMetadata(10)
+	require.NoError(t, c.SealMaster(ctx, metadata, PrimaryOnlyEvergreenProfile(artistAcct.Address, platformAcct.Address)))
+
+	store := iinft.NewMemoryTxStore()
+	m, err := iinft.NewTxManager(c, store, iinft.TxManagerOptions{
+		PollInterval: 10 * time.Millisecond,
+		// treat unknown transactions as expired as soon as a new block is sealed
+		Expiry: 1,
+	})
+	require.NoError(t, err)
+
+	// pendingRecord simulates a submission that was interrupted before its outcome was known
+	pendingRecord := func(modID uint64) *iinft.TxRecord {
+		return &iinft.TxRecord{
+			ModID: modID,
+			Request: iinft.MintRequest{
+				Buyer:       buyerAcctName,
+				AssetID:     metadata.Asset,
+				NumEditions: 1,
+				UnitPrice:   "10.00000000",
+				Token:       flowToken,
+			},
+			Status: iinft.TxStatusPending,
+			Attempts: []*iinft.TxAttempt{
+				{TransactionID: flow.HexToID("01")},
+			},
+		}
+	}
+
+	t.Run("Should mint once per modID", func(t *testing.T) {
+		ids, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 2, "10.0", flowToken, 1001, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 2)
+
+		repeatedIDs, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 2, "10.0", flowToken, 1001, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		assert.Equal(t, ids, repeatedIDs)
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 2)
+
+		rec, found, err := store.Load(ctx, 1001)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, iinft.TxStatusSealed, rec.Status)
+		assert.Len(t, rec.Attempts, 1)
+		assert.Equal(t, rec.Attempts[0].TransactionID, rec.TransactionID)
+		assert.Equal(t, ids, rec.NFTIDs)
+		assert.Equal(t, iinft.MintRequest{
+			Buyer:       buyerAcctName,
+			AssetID:     metadata.Asset,
+			NumEditions: 2,
+			UnitPrice:   "10.00000000",
+			Token:       flowToken,
+		}, rec.Request)
+	})
+
+	t.Run("Should reject another request with the same modID", func(t *testing.T) {
+		_, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 3, "10.0", flowToken, 1001, iinft.MintOnDemandParameters{})
+		require.ErrorIs(t, err, iinft.ErrModIDMismatch)
+
+		_, err = m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 2, "5.0", flowToken, 1001, iinft.MintOnDemandParameters{})
+		require.ErrorIs(t, err, iinft.ErrModIDMismatch)
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 2)
+	})
+
+	t.Run("Should resubmit expired transactions", func(t *testing.T) {
+		require.NoError(t, store.Save(ctx, pendingRecord(1002)))
+
+		ids, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 1, "10.0", flowToken, 1002, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 3)
+
+		rec, _, err := store.Load(ctx, 1002)
+		require.NoError(t, err)
+		assert.Equal(t, iinft.TxStatusSealed, rec.Status)
+		assert.Len(t, rec.Attempts, 2)
+		assert.Equal(t, rec.Attempts[1].TransactionID, rec.TransactionID)
+	})
+
+	t.Run("Should detect prior success from Minted events", func(t *testing.T) {
+		ids, err := c.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 1, "10.0", flowToken, 1003, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+
+		require.NoError(t, store.Save(ctx, pendingRecord(1003)))
+
+		foundIDs, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 1, "10.0", flowToken, 1003, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		assert.Equal(t, ids, foundIDs)
+
+		checkDigitalArtCollectionLen(t, se, buyerAcct.Address.String(), 4)
+
+		rec, _, err := store.Load(ctx, 1003)
+		require.NoError(t, err)
+		assert.Equal(t, iinft.TxStatusSealed, rec.Status)
+		assert.Len(t, rec.Attempts, 1)
+		assert.NotEqual(t, flow.EmptyID, rec.TransactionID)
+	})
+
+	t.Run("Should record failed transactions", func(t *testing.T) {
+		_, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 100, "10.0", flowToken, 1004, iinft.MintOnDemandParameters{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many editions requested")
+
+		rec, _, err := store.Load(ctx, 1004)
+		require.NoError(t, err)
+		assert.Equal(t, iinft.TxStatusFailed, rec.Status)
+
+		ids, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 1, "10.0", flowToken, 1004, iinft.MintOnDemandParameters{})
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+
+		rec, _, err = store.Load(ctx, 1004)
+		require.NoError(t, err)
+		assert.Equal(t, iinft.TxStatusSealed, rec.Status)
+		assert.Len(t, rec.Attempts, 2)
+	})
+
+	t.Run("Should require modID", func(t *testing.T) {
+		_, err := m.MintOnDemand(ctx, buyerAcctName, metadata.Asset, 1, "10.0", flowToken, 0, iinft.MintOnDemandParameters{})
+		assert.ErrorIs(t, err, iinft.ErrNoModID)
+	})
+}
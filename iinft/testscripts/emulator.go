@@ -0,0 +1,249 @@
+package testscripts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-emulator/adapters"
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/gateway"
+	"github.com/piprate/splash"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/status"
+)
+
+// EmulatorGateway is a flowkit gateway to an in-memory emulator, like splash.EmulatorGateway,
+// but it holds the emulated blockchain, so that tests can control its clock.
+type EmulatorGateway struct {
+	blockchain *emulator.Blockchain
+	adapter    *adapters.SDKAdapter
+}
+
+var _ gateway.Gateway = (*EmulatorGateway)(nil)
+
+// NewInMemoryTestConnector creates a connector to an in-memory emulator, like
+// splash.NewInMemoryTestConnector, which block timestamps can be set with AdvanceBlockTime.
+// Connectors created by this function don't support iinft.DryRun.
+func NewInMemoryTestConnector(baseDir string, enableTxFees bool) (*splash.Connector, error) {
+	state, err := flowkit.Load([]string{config.DefaultPath}, splash.NewFileSystemLoader(baseDir))
+	if err != nil {
+		return nil, err
+	}
+
+	serviceAcct, err := state.EmulatorServiceAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := serviceAcct.Key.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	blockchain, err := emulator.New(
+		emulator.WithServicePublicKey((*pk).PublicKey(), serviceAcct.Key.SigAlgo(), serviceAcct.Key.HashAlgo()),
+		emulator.WithTransactionFeesEnabled(enableTxFees),
+	)
+	if err != nil {
+		return nil, err
+	}
+	blockchain.EnableAutoMine()
+
+	nopLogger := zerolog.Nop()
+	gw := &EmulatorGateway{
+		blockchain: blockchain,
+		adapter:    adapters.NewSDKAdapter(&nopLogger, blockchain),
+	}
+
+	logger := splash.NewZeroLogger()
+
+	return &splash.Connector{
+		State:                        state,
+		Services:                     flowkit.NewFlowkit(state, config.EmulatorNetwork, gw, logger),
+		Logger:                       logger,
+		PrependNetworkToAccountNames: true,
+		Network:                      config.EmulatorNetwork.Name,
+	}, nil
+}
+
+// SetTime makes the emulator timestamp new blocks with the given time, until the next call,
+// and commits a block, so that both scripts and transactions see the new timestamp.
+func (g *EmulatorGateway) SetTime(now time.Time) error {
+	g.blockchain.SetClock(fixedClock(now))
+
+	_, _, err := g.blockchain.ExecuteAndCommitBlock()
+	return err
+}
+
+// fixedClock is an emulator clock that always returns the same time.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func (g *EmulatorGateway) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
+	account, err := g.adapter.GetAccount(ctx, address)
+	return account, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetAccountAtBlockHeight(ctx context.Context, address flow.Address, height uint64) (*flow.Account, error) {
+	account, err := g.adapter.GetAccountAtBlockHeight(ctx, address, height)
+	return account, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) SendSignedTransaction(ctx context.Context, tx *flow.Transaction) (*flow.Transaction, error) {
+	if err := g.adapter.SendTransaction(ctx, *tx); err != nil {
+		return nil, unwrapStatusError(err)
+	}
+	return tx, nil
+}
+
+func (g *EmulatorGateway) GetTransaction(ctx context.Context, id flow.Identifier) (*flow.Transaction, error) {
+	tx, err := g.adapter.GetTransaction(ctx, id)
+	return tx, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetTransactionResultsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*flow.TransactionResult, error) {
+	results, err := g.adapter.GetTransactionResultsByBlockID(ctx, blockID)
+	return results, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetTransactionResult(ctx context.Context, id flow.Identifier, _ bool) (*flow.TransactionResult, error) {
+	result, err := g.adapter.GetTransactionResult(ctx, id)
+	return result, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetTransactionsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*flow.Transaction, error) {
+	txs, err := g.adapter.GetTransactionsByBlockID(ctx, blockID)
+	return txs, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetSystemTransaction(_ context.Context, _ flow.Identifier) (*flow.Transaction, error) {
+	return nil, nil
+}
+
+func (g *EmulatorGateway) GetSystemTransactionResult(_ context.Context, _ flow.Identifier) (*flow.TransactionResult, error) {
+	return nil, nil
+}
+
+func (g *EmulatorGateway) GetSystemTransactionWithID(_ context.Context, _ flow.Identifier, _ flow.Identifier) (*flow.Transaction, error) {
+	return nil, nil
+}
+
+func (g *EmulatorGateway) GetSystemTransactionResultWithID(_ context.Context, _ flow.Identifier, _ flow.Identifier) (*flow.TransactionResult, error) {
+	return nil, nil
+}
+
+func (g *EmulatorGateway) ExecuteScript(ctx context.Context, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+	args, err := encodeArguments(arguments)
+	if err != nil {
+		return nil, err
+	}
+	return decodeScriptResult(g.adapter.ExecuteScriptAtLatestBlock(ctx, script, args))
+}
+
+func (g *EmulatorGateway) ExecuteScriptAtHeight(ctx context.Context, script []byte, arguments []cadence.Value, height uint64) (cadence.Value, error) {
+	args, err := encodeArguments(arguments)
+	if err != nil {
+		return nil, err
+	}
+	return decodeScriptResult(g.adapter.ExecuteScriptAtBlockHeight(ctx, height, script, args))
+}
+
+func (g *EmulatorGateway) ExecuteScriptAtID(ctx context.Context, script []byte, arguments []cadence.Value, blockID flow.Identifier) (cadence.Value, error) {
+	args, err := encodeArguments(arguments)
+	if err != nil {
+		return nil, err
+	}
+	return decodeScriptResult(g.adapter.ExecuteScriptAtBlockID(ctx, blockID, script, args))
+}
+
+func (g *EmulatorGateway) GetLatestBlock(ctx context.Context) (*flow.Block, error) {
+	block, _, err := g.adapter.GetLatestBlock(ctx, true)
+	return block, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetBlockByHeight(ctx context.Context, height uint64) (*flow.Block, error) {
+	block, _, err := g.adapter.GetBlockByHeight(ctx, height)
+	return block, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetBlockByID(ctx context.Context, blockID flow.Identifier) (*flow.Block, error) {
+	block, _, err := g.adapter.GetBlockByID(ctx, blockID)
+	return block, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetEvents(ctx context.Context, eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error) {
+	blockEvents, err := g.adapter.GetEventsForHeightRange(ctx, eventType, startHeight, endHeight)
+	if err != nil {
+		return nil, unwrapStatusError(err)
+	}
+
+	res := make([]flow.BlockEvents, len(blockEvents))
+	for i, be := range blockEvents {
+		res[i] = *be
+	}
+	return res, nil
+}
+
+func (g *EmulatorGateway) GetCollection(ctx context.Context, id flow.Identifier) (*flow.Collection, error) {
+	collection, err := g.adapter.GetCollectionByID(ctx, id)
+	return collection, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) GetLatestProtocolStateSnapshot(ctx context.Context) ([]byte, error) {
+	snapshot, err := g.adapter.GetLatestProtocolStateSnapshot(ctx)
+	return snapshot, unwrapStatusError(err)
+}
+
+func (g *EmulatorGateway) Ping() error {
+	return unwrapStatusError(g.adapter.Ping(context.Background()))
+}
+
+func (g *EmulatorGateway) WaitServer(_ context.Context) error {
+	return nil
+}
+
+func (g *EmulatorGateway) SecureConnection() bool {
+	return false
+}
+
+// unwrapStatusError replaces gRPC status errors with their messages, as splash.EmulatorGateway does.
+func unwrapStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(status.Convert(err).Message())
+}
+
+func encodeArguments(values []cadence.Value) ([][]byte, error) {
+	args := make([][]byte, len(values))
+	for i, val := range values {
+		arg, err := jsoncdc.Encode(val)
+		if err != nil {
+			return nil, fmt.Errorf("convert: %w", err)
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+func decodeScriptResult(result []byte, err error) (cadence.Value, error) {
+	if err != nil {
+		return nil, unwrapStatusError(err)
+	}
+
+	value, err := jsoncdc.Decode(nil, result)
+	if err != nil {
+		return nil, fmt.Errorf("convert: %w", err)
+	}
+	return value, nil
+}
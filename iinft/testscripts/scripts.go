@@ -3,6 +3,7 @@ package testscripts
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
@@ -18,17 +19,25 @@ func ConfigureInMemoryEmulator(t *testing.T, client *splash.Connector, adminFlow
 	_, err := client.DoNotPrependNetworkToAccountNames().CreateAccountsE(context.Background(), "emulator-account")
 	require.NoError(t, err)
 
-	if adminFlowDeposit != "" {
-		adminAcct := client.Account("emulator-sequel-admin")
+	err = iinft.InitializeContractsE(context.Background(), client, "emulator-sequel-admin", adminFlowDeposit)
+	require.NoError(t, err)
 
-		se, err := iinft.NewTemplateEngine(client)
-		require.NoError(t, err)
+	err = iinft.DeployOptionalContractsE(context.Background(), client, "emulator-sequel-admin", "", iinft.OptionalContracts...)
+	require.NoError(t, err)
+}
 
-		FundAccountWithFlow(t, se, adminAcct.Address, adminFlowDeposit)
-	}
+// AdvanceBlockTime sets the in-memory emulator's clock to the given time and commits
+// an empty block, so that both scripts and transactions see it as the block timestamp.
+// The clock stays at the given time until the next call, so that tests see
+// predictable timestamps. The time must not be earlier than the latest block's timestamp.
+// The connector must be created with NewInMemoryTestConnector.
+func AdvanceBlockTime(t *testing.T, client *splash.Connector, until time.Time) {
+	t.Helper()
 
-	err = client.InitializeContractsE(context.Background())
-	require.NoError(t, err)
+	gw, ok := client.Services.Gateway().(*EmulatorGateway)
+	require.True(t, ok, "the connector isn't created by testscripts.NewInMemoryTestConnector")
+
+	require.NoError(t, gw.SetTime(until))
 }
 
 func FundAccountWithFlow(t *testing.T, se *splash.TemplateEngine, receiverAddress flow.Address, amount string) {
@@ -110,3 +119,14 @@ func CreateSealDigitalArtTx(t *testing.T, se *splash.TemplateEngine, client *spl
 
 	return tx
 }
+
+// ProfileToCadenceUnchecked converts the given profile into a Cadence value, bypassing
+// profile validation. Use it to check how contracts handle invalid profiles.
+func ProfileToCadenceUnchecked(t *testing.T, profile *evergreen.Profile, evergreenAddr flow.Address) cadence.Value {
+	t.Helper()
+
+	val, err := evergreen.ProfileToCadenceUnvalidated(profile, evergreenAddr)
+	require.NoError(t, err)
+
+	return val
+}
@@ -0,0 +1,463 @@
+package iinft
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit/v2"
+	"github.com/piprate/splash"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultTxPollInterval is the interval between transaction status checks.
+	DefaultTxPollInterval = time.Second
+	// DefaultTxExpiry is the number of blocks after the reference block when
+	// Flow transactions expire.
+	DefaultTxExpiry = 600
+	// DefaultMaxTxAttempts is the maximum number of transactions submitted
+	// for a mint request in one call, if the previous ones expire.
+	DefaultMaxTxAttempts = 3
+)
+
+var (
+	ErrNoModID         = errors.New("modID not specified")
+	ErrMintInProgress  = errors.New("mint with the same modID is already in progress")
+	ErrTooManyAttempts = errors.New("too many expired transactions")
+	// ErrModIDMismatch is returned if the modID was already used for a mint request
+	// with other parameters.
+	ErrModIDMismatch = errors.New("modID was used for another mint request")
+	// ErrKeyPoolNotSupported is returned by NewTxManager if the client has a key pool.
+	ErrKeyPoolNotSupported = errors.New("tx manager doesn't support clients with a key pool")
+)
+
+// Statuses of mint requests tracked by TxManager.
+const (
+	// TxStatusPending means that the last transaction was submitted, but its result is unknown.
+	TxStatusPending TxStatus = "pending"
+	// TxStatusExpired means that the last transaction expired without being executed,
+	// and no tokens were minted for the request.
+	TxStatusExpired TxStatus = "expired"
+	// TxStatusSealed means that the tokens were minted.
+	TxStatusSealed TxStatus = "sealed"
+	// TxStatusFailed means that the last transaction was executed, but failed.
+	TxStatusFailed TxStatus = "failed"
+)
+
+type (
+	// TxStatus is the status of a mint request tracked by TxManager.
+	TxStatus string
+
+	// TxAttempt describes a transaction submitted for a mint request.
+	TxAttempt struct {
+		TransactionID flow.Identifier
+		// ReferenceHeight is the height of the latest sealed block when the transaction was built.
+		// It's not greater than the height of the transaction's reference block.
+		ReferenceHeight uint64
+		SubmittedAt     time.Time
+	}
+
+	// MintRequest holds the parameters of a mint-on-demand request.
+	MintRequest struct {
+		Buyer       string
+		AssetID     string
+		NumEditions uint64
+		// UnitPrice is a decimal string with 8 fractional digits, i.e. "10.00000000".
+		UnitPrice string
+		Token     FungibleTokenContract
+	}
+
+	// TxRecord tracks transactions submitted for the mint-on-demand request with the given modID.
+	TxRecord struct {
+		ModID   uint64
+		Request MintRequest
+		Status  TxStatus
+		// Attempts lists the submitted transactions, from the earliest to the most recent one.
+		Attempts []*TxAttempt
+		// TransactionID is the ID of the transaction that minted the tokens.
+		TransactionID flow.Identifier
+		// NFTIDs lists the IDs of the minted tokens.
+		NFTIDs []uint64
+		// Error is the error message of the failed transaction.
+		Error     string
+		UpdatedAt time.Time
+	}
+
+	// TxStore persists mint request records, so that TxManager can resume tracking
+	// of submitted transactions after a restart.
+	TxStore interface {
+		// Load returns the record for the given modID. If no record was saved yet,
+		// found is false.
+		Load(ctx context.Context, modID uint64) (rec *TxRecord, found bool, err error)
+		// Save creates or replaces the record.
+		Save(ctx context.Context, rec *TxRecord) error
+	}
+
+	// MemoryTxStore keeps mint request records in memory.
+	MemoryTxStore struct {
+		records map[uint64]*TxRecord
+		mutex   sync.Mutex
+	}
+
+	TxManagerOptions struct {
+		// PollInterval is the interval between transaction status checks.
+		PollInterval time.Duration
+		// Expiry is the number of blocks after the reference block when a transaction
+		// that isn't known to the access node is considered expired.
+		Expiry uint64
+		// MaxAttempts is the maximum number of transactions submitted in one call,
+		// if the previous ones expire.
+		MaxAttempts int
+	}
+
+	// TxManager submits mint-on-demand transactions and tracks them until they are sealed.
+	// It uses modID as an idempotency key: each mint request is minted at most once,
+	// even if the caller repeats it after a timeout or a restart.
+	//
+	// Transaction IDs are saved to the store before the transactions are submitted.
+	// When the outcome of a transaction is unknown, the manager waits until it's sealed
+	// or expired. Before resubmitting an expired transaction, it scans DigitalArt.Minted
+	// events for the same modID to detect prior success.
+	//
+	// The manager signs transactions with the client's admin account key. It tracks
+	// the transactions itself, so it doesn't accept clients with a key pool (see WithKeyPool).
+	TxManager struct {
+		client   *Client
+		store    TxStore
+		opts     TxManagerOptions
+		inFlight map[uint64]bool
+		mutex    sync.Mutex
+	}
+)
+
+// NewMemoryTxStore creates a new in-memory mint request store.
+func NewMemoryTxStore() *MemoryTxStore {
+	return &MemoryTxStore{
+		records: make(map[uint64]*TxRecord),
+	}
+}
+
+func (s *MemoryTxStore) Load(_ context.Context, modID uint64) (*TxRecord, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rec, found := s.records[modID]
+	if !found {
+		return nil, false, nil
+	}
+
+	return rec.clone(), true, nil
+}
+
+func (s *MemoryTxStore) Save(_ context.Context, rec *TxRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records[rec.ModID] = rec.clone()
+
+	return nil
+}
+
+func (r *TxRecord) clone() *TxRecord {
+	res := *r
+	res.Attempts = make([]*TxAttempt, len(r.Attempts))
+	for i, a := range r.Attempts {
+		attempt := *a
+		res.Attempts[i] = &attempt
+	}
+	res.NFTIDs = slices.Clone(r.NFTIDs)
+
+	return &res
+}
+
+// NewTxManager creates a new TxManager. It returns ErrKeyPoolNotSupported if the client
+// has a key pool.
+func NewTxManager(client *Client, store TxStore, opts TxManagerOptions) (*TxManager, error) {
+	if client.keyPool != nil {
+		return nil, ErrKeyPoolNotSupported
+	}
+
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultTxPollInterval
+	}
+	if opts.Expiry == 0 {
+		opts.Expiry = DefaultTxExpiry
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = DefaultMaxTxAttempts
+	}
+
+	return &TxManager{
+		client:   client,
+		store:    store,
+		opts:     opts,
+		inFlight: make(map[uint64]bool),
+	}, nil
+}
+
+// MintOnDemand works like Client.MintOnDemand, but mints the tokens at most once
+// for the given modID. If the tokens were already minted, it returns their IDs without
+// submitting a new transaction. If the previous call was interrupted, it resumes
+// tracking of the submitted transaction. If the last transaction failed, it submits
+// a new one. It returns ErrModIDMismatch if the modID was used for a request with another
+// buyer, asset, number of editions, price or token, unless no tokens were minted for it.
+func (m *TxManager) MintOnDemand(ctx context.Context, buyer string, assetID string, numEditions uint64, unitPrice string,
+	token FungibleTokenContract, modID uint64, params MintOnDemandParameters) ([]uint64, error) {
+	if modID == 0 {
+		return nil, ErrNoModID
+	}
+
+	if !m.acquire(modID) {
+		return nil, ErrMintInProgress
+	}
+	defer m.release(modID)
+
+	price, err := cadence.NewUFix64(unitPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	req := MintRequest{
+		Buyer:       buyer,
+		AssetID:     assetID,
+		NumEditions: numEditions,
+		UnitPrice:   price.String(),
+		Token:       token,
+	}
+
+	rec, found, err := m.store.Load(ctx, modID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !found:
+		rec = &TxRecord{ModID: modID, Request: req}
+	case rec.Status == TxStatusFailed || rec.Status == TxStatusExpired:
+		// no tokens were minted, so the request may change
+		rec.Request = req
+	case rec.Request != req:
+		return nil, ErrModIDMismatch
+	}
+
+	for submitted := 0; ; {
+		if rec.Status == TxStatusPending {
+			if err = m.await(ctx, rec); err != nil {
+				return nil, err
+			}
+		}
+
+		switch rec.Status {
+		case TxStatusSealed:
+			return rec.NFTIDs, nil
+		case TxStatusFailed:
+			if submitted > 0 {
+				return nil, errors.New(rec.Error)
+			}
+		}
+
+		if submitted == m.opts.MaxAttempts {
+			return nil, ErrTooManyAttempts
+		}
+
+		tb, err := m.client.mintOnDemandTransaction(buyer, assetID, numEditions, unitPrice, token, modID, params)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = m.submit(ctx, rec, tb); err != nil {
+			return nil, err
+		}
+		submitted++
+	}
+}
+
+// FindMinted scans DigitalArt.Minted events in sealed blocks from startHeight onwards
+// and returns the ID of the transaction that minted tokens for the given modID
+// and the IDs of the tokens. If no such tokens were found, it returns flow.EmptyID.
+func (m *TxManager) FindMinted(ctx context.Context, modID uint64, startHeight uint64) (flow.Identifier, []uint64, error) {
+	endHeight, err := m.latestHeight(ctx)
+	if err != nil {
+		return flow.EmptyID, nil, err
+	}
+	if endHeight < startHeight {
+		return flow.EmptyID, nil, nil
+	}
+
+	eventType := EventTypeID(m.client.se.ContractAddress("DigitalArt"), "DigitalArt", "Minted")
+	blockEvents, err := m.client.client.Services.GetEvents(ctx, []string{eventType}, startHeight, endHeight, nil)
+	if err != nil {
+		return flow.EmptyID, nil, err
+	}
+
+	txID := flow.EmptyID
+	var ids []uint64
+	for _, be := range blockEvents {
+		for _, ev := range be.Events {
+			minted, err := MintedEventFromCadence(ev.Value)
+			if err != nil {
+				return flow.EmptyID, nil, err
+			}
+			if minted.ModID != modID {
+				continue
+			}
+			txID = ev.TransactionID
+			ids = append(ids, minted.ID)
+		}
+	}
+
+	return txID, ids, nil
+}
+
+func (m *TxManager) acquire(modID uint64) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.inFlight[modID] {
+		return false
+	}
+	m.inFlight[modID] = true
+
+	return true
+}
+
+func (m *TxManager) release(modID uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.inFlight, modID)
+}
+
+// submit saves a new attempt to the store and sends the transaction without waiting
+// for the result. If the access node rejects the transaction, the record is marked as expired.
+func (m *TxManager) submit(ctx context.Context, rec *TxRecord, tb splash.FlowTransactionBuilder) error {
+	height, err := m.latestHeight(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := buildSignedTransaction(ctx, tb, tb.Connector.Account(m.client.adminAccount), nil)
+	if err != nil {
+		return err
+	}
+
+	rec.Attempts = append(rec.Attempts, &TxAttempt{
+		TransactionID:   tx.FlowTransaction().ID(),
+		ReferenceHeight: height,
+		SubmittedAt:     time.Now(),
+	})
+	rec.Status = TxStatusPending
+	rec.Error = ""
+	if err = m.save(ctx, rec); err != nil {
+		return err
+	}
+
+	if _, err = tb.Connector.Services.Gateway().SendSignedTransaction(ctx, tx.FlowTransaction()); err != nil {
+		if isRejectedTransactionError(err) {
+			rec.Status = TxStatusExpired
+			return m.save(ctx, rec)
+		}
+		// the transaction may or may not have been accepted, so it stays pending
+		return err
+	}
+
+	return nil
+}
+
+// await polls the status of the last submitted transaction until it's sealed or expired.
+func (m *TxManager) await(ctx context.Context, rec *TxRecord) error {
+	attempt := rec.Attempts[len(rec.Attempts)-1]
+	gw := m.client.client.Services.Gateway()
+
+	for {
+		res, err := gw.GetTransactionResult(ctx, attempt.TransactionID, false)
+		if err != nil && !isNotFoundError(err) {
+			return err
+		}
+
+		if err == nil && res.Status == flow.TransactionStatusSealed {
+			return m.complete(ctx, rec, attempt.TransactionID, res)
+		}
+
+		if err == nil && res.Status == flow.TransactionStatusExpired {
+			return m.expire(ctx, rec)
+		}
+
+		if err != nil || res.Status == flow.TransactionStatusUnknown {
+			height, err := m.latestHeight(ctx)
+			if err != nil {
+				return err
+			}
+			if height > attempt.ReferenceHeight+m.opts.Expiry {
+				return m.expire(ctx, rec)
+			}
+		}
+
+		select {
+		case <-time.After(m.opts.PollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *TxManager) complete(ctx context.Context, rec *TxRecord, txID flow.Identifier, res *flow.TransactionResult) error {
+	if res.Error != nil {
+		rec.Status = TxStatusFailed
+		rec.Error = res.Error.Error()
+		return m.save(ctx, rec)
+	}
+
+	ids, err := m.client.mintedIDs(res)
+	if err != nil {
+		return err
+	}
+
+	rec.Status = TxStatusSealed
+	rec.TransactionID = txID
+	rec.NFTIDs = ids
+
+	return m.save(ctx, rec)
+}
+
+// expire marks the record as expired, unless the tokens were minted by one of the earlier
+// transactions that the access node doesn't know about anymore.
+func (m *TxManager) expire(ctx context.Context, rec *TxRecord) error {
+	txID, ids, err := m.FindMinted(ctx, rec.ModID, rec.Attempts[0].ReferenceHeight)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		rec.Status = TxStatusSealed
+		rec.TransactionID = txID
+		rec.NFTIDs = ids
+	} else {
+		rec.Status = TxStatusExpired
+	}
+
+	return m.save(ctx, rec)
+}
+
+func (m *TxManager) save(ctx context.Context, rec *TxRecord) error {
+	rec.UpdatedAt = time.Now()
+	return m.store.Save(ctx, rec)
+}
+
+func (m *TxManager) latestHeight(ctx context.Context) (uint64, error) {
+	block, err := m.client.client.Services.GetBlock(ctx, flowkit.LatestBlockQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	return block.Height, nil
+}
+
+func isNotFoundError(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
@@ -3,9 +3,6 @@ package iinft
 import (
 	"errors"
 
-	"github.com/onflow/cadence"
-	"github.com/onflow/cadence/common"
-	"github.com/onflow/flow-go-sdk"
 	"github.com/piprate/sequel-flow-contracts/iinft/evergreen"
 )
 
@@ -65,117 +62,13 @@ type (
 	}
 )
 
-func DigitalArtMetadataFromCadence(val cadence.Value) (*DigitalArtMetadata, error) {
-	if opt, ok := val.(cadence.Optional); ok {
-		if opt.Value == nil {
-			return nil, nil
-		}
-		val = opt.Value
+// Validate checks the parameters before they are rendered into a transaction template.
+func (p *MintOnDemandParameters) Validate() error {
+	if p.Metadata == nil {
+		return nil
 	}
-
-	valStruct, ok := val.(cadence.Struct)
-	if !ok || valStruct.StructType.QualifiedIdentifier != "DigitalArt.Metadata" || len(valStruct.FieldsMappedByName()) != 13 {
-		return nil, errors.New("bad Metadata value")
+	if p.Profile == nil {
+		return errors.New("missing Evergreen profile required to seal the master")
 	}
-
-	allFields := valStruct.FieldsMappedByName()
-
-	res := DigitalArtMetadata{
-		Name:              string(allFields["name"].(cadence.String)),
-		Artist:            string(allFields["artist"].(cadence.String)),
-		Description:       string(allFields["description"].(cadence.String)),
-		Type:              string(allFields["type"].(cadence.String)),
-		ContentURI:        string(allFields["contentURI"].(cadence.String)),
-		ContentPreviewURI: string(allFields["contentPreviewURI"].(cadence.String)),
-		ContentMimetype:   string(allFields["mimetype"].(cadence.String)),
-		Edition:           uint64(allFields["edition"].(cadence.UInt64)),
-		MaxEdition:        uint64(allFields["maxEdition"].(cadence.UInt64)),
-		Asset:             string(allFields["asset"].(cadence.String)),
-		MetadataURI:       string(allFields["metadataURI"].(cadence.String)),
-		Record:            string(allFields["record"].(cadence.String)),
-		AssetHead:         string(allFields["assetHead"].(cadence.String)),
-	}
-
-	return &res, nil
-}
-
-func DigitalArtMetadataToCadence(metadata *DigitalArtMetadata, digitalArtAddr flow.Address) cadence.Value {
-	return cadence.NewStruct([]cadence.Value{
-		cadence.String(metadata.Name),
-		cadence.String(metadata.Artist),
-		cadence.String(metadata.Description),
-		cadence.String(metadata.Type),
-		cadence.String(metadata.ContentURI),
-		cadence.String(metadata.ContentPreviewURI),
-		cadence.String(metadata.ContentMimetype),
-		cadence.UInt64(metadata.Edition),
-		cadence.UInt64(metadata.MaxEdition),
-		cadence.String(metadata.Asset),
-		cadence.String(metadata.MetadataURI),
-		cadence.String(metadata.Record),
-		cadence.String(metadata.AssetHead),
-	}).WithType(cadence.NewStructType(
-		common.AddressLocation{
-			Address: common.Address(digitalArtAddr),
-			Name:    common.AddressLocationPrefix,
-		},
-		"DigitalArt.Metadata",
-		metadataCadenceFields,
-		nil,
-	))
-}
-
-var metadataCadenceFields = []cadence.Field{
-	{
-		Identifier: "name",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "artist",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "description",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "type",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "contentURI",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "contentPreviewURI",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "mimetype",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "edition",
-		Type:       cadence.UInt64Type,
-	},
-	{
-		Identifier: "maxEdition",
-		Type:       cadence.UInt64Type,
-	},
-	{
-		Identifier: "asset",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "metadataURI",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "record",
-		Type:       cadence.StringType,
-	},
-	{
-		Identifier: "assetHead",
-		Type:       cadence.StringType,
-	},
+	return p.Profile.Validate()
 }
@@ -4,4 +4,6 @@ import "embed"
 
 //go:embed contracts
 //go:embed flow.json
+//go:embed flow.mainnet.json
+//go:embed flow.testnet.json
 var ResourcesFS embed.FS